@@ -18,12 +18,15 @@ import (
 	"syscall"
 	"time"
 
+	"encoder/pkg/video"
+
 	wails_runtime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
-	ctx context.Context
+	ctx        context.Context
+	probeCache *video.ProbeCache // 모든 드롭에서 공유하는 ffprobe 결과 캐시
 }
 
 // NewApp은 새 앱 애플리케이션 구조를 생성합니다.
@@ -35,6 +38,13 @@ func NewApp() *App {
 func (a *App) startup(ctx context.Context) {
 	// Perform your setup here
 	a.ctx = ctx
+
+	// 이전에 분석한 파일의 ffprobe 결과 캐시
+	if cachePath, err := video.DefaultProbeCachePath(); err != nil {
+		wails_runtime.LogWarningf(ctx, "probe results will not be cached: %v", err)
+	} else if a.probeCache, err = video.LoadProbeCache(cachePath); err != nil {
+		wails_runtime.LogWarningf(ctx, "%v", err)
+	}
 }
 
 // domReady는 프론트엔드 리소스가 로드된 후에 호출됩니다.
@@ -220,37 +230,26 @@ func (a *App) ProcessVideo(filePath string) (*VideoMetadata, error) {
 }
 
 // ProcessVideoPaths는 여러 경로에서 비디오 파일을 찾아 처리합니다
+// 결과와 건너뛴 파일은 이벤트로 전송되며, ffprobe는 video.Prober의 워커 수만큼만 동시에 실행됩니다
 func (a *App) ProcessVideoPaths(paths []string) error {
-	// 메타데이터를 전송할 채널 생성
-	for _, path := range paths {
-		// 각 경로에 대해 고루틴으로 처리
-		go func(path string) {
-			// 각 경로에 대해 비디오 파일 찾기
-			videoPaths, err := a.FindVideoFiles(path)
-			if err != nil {
-				wails_runtime.EventsEmit(a.ctx, "video_error", map[string]string{
-					"path":  path,
-					"error": fmt.Sprintf("경로 처리 중 오류 발생: %v", err),
-				})
-				return
-			}
-
-			// 찾은 각 비디오 파일에 대해 메타데이터 추출 및 전송
-			for _, videoPath := range videoPaths {
-				metadata, err := a.ProcessVideo(videoPath)
-				if err != nil {
-					wails_runtime.EventsEmit(a.ctx, "video_error", map[string]string{
-						"path":  videoPath,
-						"error": fmt.Sprintf("비디오 처리 중 오류 발생: %v", err),
-					})
-					continue
-				}
-
-				// 메타데이터를 이벤트로 전송
-				wails_runtime.EventsEmit(a.ctx, "video_processed", metadata)
-			}
-		}(path)
+	prober := &video.Prober{
+		Cache: a.probeCache,
+		OnResult: func(metadata *video.VideoMetadata) {
+			wails_runtime.EventsEmit(a.ctx, "video_processed", metadata)
+		},
+		OnError: func(probeErr video.ProbeError) {
+			wails_runtime.EventsEmit(a.ctx, "video_error", probeErr)
+		},
+		OnSkipped: func(file video.SkippedFile) {
+			wails_runtime.EventsEmit(a.ctx, "video_skipped", file)
+		},
 	}
+	go func() {
+		prober.ProcessPaths(paths)
+		if err := a.probeCache.Save(); err != nil {
+			wails_runtime.LogWarningf(a.ctx, "%v", err)
+		}
+	}()
 
 	return nil
 }
@@ -5,18 +5,27 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"time"
 
 	"encoder/pkg/codec"
 	"encoder/pkg/encoder"
+	"encoder/pkg/ffmpeg"
 	"encoder/pkg/video"
+	"encoder/pkg/watch"
 
 	wails_runtime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// shutdownTimeout is how long Shutdown waits for a cancelled batch to clean up
+const shutdownTimeout = 5 * time.Second
+
 // App struct
 type App struct {
-	ctx     context.Context
-	encoder *encoder.Encoder
+	ctx        context.Context
+	encoder    *encoder.Encoder
+	queue      *encoder.Queue
+	probeCache *video.ProbeCache
+	watcher    *watch.Watcher
 }
 
 // NewApp creates a new App application struct
@@ -28,6 +37,42 @@ func NewApp() *App {
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
 	a.encoder = encoder.NewEncoder(ctx)
+
+	// 이전에 분석한 파일의 ffprobe 결과 캐시
+	if cachePath, err := video.DefaultProbeCachePath(); err != nil {
+		wails_runtime.LogWarningf(ctx, "probe results will not be cached: %v", err)
+	} else if a.probeCache, err = video.LoadProbeCache(cachePath); err != nil {
+		wails_runtime.LogWarningf(ctx, "%v", err)
+	}
+
+	// 이전 실행에서 남은 작업 큐 불러오기
+	a.queue = &encoder.Queue{}
+	if queuePath, err := encoder.DefaultQueuePath(); err != nil {
+		wails_runtime.LogErrorf(ctx, "job queue will not be saved: %v", err)
+	} else if a.queue, err = encoder.LoadQueue(queuePath); err != nil {
+		wails_runtime.LogErrorf(ctx, "failed to load job queue: %v", err)
+	}
+
+	// 비정상 종료로 남은 임시 출력 파일 정리
+	for _, err := range encoder.SweepPartials(a.queue.PartialPaths()) {
+		wails_runtime.LogWarningf(ctx, "%v", err)
+	}
+
+	// 감시 폴더에 새로 들어온 파일 자동 인코딩
+	watchPath, err := watch.DefaultConfigPath()
+	if err != nil {
+		wails_runtime.LogWarningf(ctx, "watch folders will not be saved: %v", err)
+	}
+	a.watcher, err = watch.New(watchPath, a.queue, func(ids []string) (*encoder.BatchResult, error) {
+		return a.encoder.RunJobs(a.queue, ids, a.EmitProgress)
+	})
+	if err != nil {
+		wails_runtime.LogErrorf(ctx, "failed to load watch folders: %v", err)
+	}
+	a.watcher.OnEvent = func(event watch.Event) {
+		wails_runtime.EventsEmit(ctx, "watch_event", event)
+	}
+	go a.watcher.Run(ctx)
 }
 
 // DomReady is called after front-end resources have been loaded
@@ -41,8 +86,18 @@ func (a *App) BeforeClose(ctx context.Context) (prevent bool) {
 	return false
 }
 
-// Shutdown is called at application termination
+// Shutdown is called at application termination. The running batch is
+// cancelled so no ffmpeg process, including a paused one, outlives the app.
 func (a *App) Shutdown(ctx context.Context) {
+	if a.encoder == nil || a.encoder.CancelEncoding() != nil {
+		return
+	}
+
+	// 종료된 ffmpeg의 임시 출력 파일이 정리될 때까지 잠시 대기
+	deadline := time.Now().Add(shutdownTimeout)
+	for a.encoder.Running() && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
 }
 
 // EmitProgress sends encoding progress updates to the frontend
@@ -52,16 +107,182 @@ func (a *App) EmitProgress(progress encoder.EncodingProgress) {
 
 // Public methods that will be called from frontend
 
-func (a *App) ProcessVideoPaths(paths []string) ([]*video.VideoMetadata, error) {
-	return video.ProcessPaths(paths)
+// ProcessVideoPaths finds the video files under paths that match the scan
+// options and probes them, emitting an event for every file as it finishes
+func (a *App) ProcessVideoPaths(paths []string, options video.ScanOptions) ([]*video.VideoMetadata, error) {
+	prober := &video.Prober{
+		Cache: a.probeCache,
+		Scan:  options,
+		OnResult: func(metadata *video.VideoMetadata) {
+			wails_runtime.EventsEmit(a.ctx, "video_processed", metadata)
+		},
+		OnError: func(probeErr video.ProbeError) {
+			wails_runtime.EventsEmit(a.ctx, "video_error", probeErr)
+		},
+		OnSkipped: func(file video.SkippedFile) {
+			wails_runtime.EventsEmit(a.ctx, "video_skipped", file)
+		},
+	}
+	results, err := prober.ProcessPaths(paths)
+
+	if saveErr := a.probeCache.Save(); saveErr != nil {
+		wails_runtime.LogWarningf(a.ctx, "%v", saveErr)
+	}
+	return results, err
+}
+
+// AddVideoExtensions makes files with the given extensions count as video
+// without inspecting their contents
+func (a *App) AddVideoExtensions(extensions []string) {
+	video.AddVideoExtensions(extensions...)
 }
 
 func (a *App) GetAvailableCodecs() ([]codec.CodecInfo, error) {
 	return codec.GetAvailable()
 }
 
-func (a *App) StartEncodingWithOptions(paths []string, options encoder.EncodingOptions) error {
-	return a.encoder.StartEncoding(paths, options, a.EmitProgress)
+// RecheckHardwareCodecs tests every hardware encoder again instead of using
+// cached results, e.g. after installing a driver
+func (a *App) RecheckHardwareCodecs() ([]codec.CodecInfo, error) {
+	return codec.ReverifyHardware()
+}
+
+// GetAvailableAudioCodecs returns the audio codecs supported by the installed ffmpeg
+func (a *App) GetAvailableAudioCodecs() ([]codec.CodecInfo, error) {
+	return codec.GetAvailableAudio()
+}
+
+// GetContainers returns the output container formats and the codecs each can hold
+func (a *App) GetContainers() []codec.Container {
+	return codec.Containers
+}
+
+// StartEncodingWithOptions adds the files to the job queue and encodes them.
+// Nothing is queued if another batch, e.g. a watch folder run, is in progress.
+func (a *App) StartEncodingWithOptions(paths []string, options encoder.EncodingOptions) (*encoder.BatchResult, error) {
+	if a.encoder.Running() {
+		return nil, encoder.ErrBusy
+	}
+	if err := options.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid encoding options: %w", err)
+	}
+
+	info, err := ffmpeg.Check()
+	if err != nil {
+		return nil, err
+	}
+	if info.Warning != "" {
+		wails_runtime.LogWarning(a.ctx, info.Warning)
+	}
+
+	jobs, err := a.queue.Add(paths, options)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+	result, err := a.encoder.RunJobs(a.queue, ids, a.EmitProgress)
+	if err != nil {
+		// 다른 배치가 먼저 시작된 경우 등: 아무도 실행하지 않을 작업을 큐에 남기지 않음
+		for _, id := range ids {
+			a.queue.Remove(id)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// PreviewOutputPaths returns the output path each input would be written to
+func (a *App) PreviewOutputPaths(paths []string, options encoder.EncodingOptions) ([]encoder.OutputPreview, error) {
+	return encoder.PreviewOutputs(paths, options)
+}
+
+// SetFFmpegPath sets the ffmpeg binary to use and checks its version.
+// An empty path restores the default lookup.
+func (a *App) SetFFmpegPath(path string) (*ffmpeg.Info, error) {
+	ffmpeg.SetFFmpegPath(path)
+	return ffmpeg.Check()
+}
+
+// SetFFprobePath sets the ffprobe binary to use. An empty path restores the default lookup.
+func (a *App) SetFFprobePath(path string) error {
+	ffmpeg.SetFFprobePath(path)
+	_, err := ffmpeg.FFprobePath()
+	return err
+}
+
+// GetFFmpegInfo returns the version and build configuration of the ffmpeg in use
+func (a *App) GetFFmpegInfo() (*ffmpeg.Info, error) {
+	return ffmpeg.Check()
+}
+
+// ListJobs returns every job in the queue in order
+func (a *App) ListJobs() []encoder.Job {
+	return a.queue.List()
+}
+
+// MoveJob moves a job to the given position in the queue
+func (a *App) MoveJob(id string, index int) error {
+	return a.queue.Move(id, index)
+}
+
+// RemoveJob deletes a job from the queue
+func (a *App) RemoveJob(id string) error {
+	return a.queue.Remove(id)
+}
+
+// ClearFinishedJobs removes all completed jobs from the queue
+func (a *App) ClearFinishedJobs() error {
+	return a.queue.ClearFinished()
+}
+
+// RetryJob puts a finished job back in the queue and encodes it
+func (a *App) RetryJob(id string) (*encoder.BatchResult, error) {
+	if a.encoder.Running() {
+		return nil, encoder.ErrBusy
+	}
+	if err := a.queue.Retry(id); err != nil {
+		return nil, err
+	}
+	return a.encoder.RunJobs(a.queue, []string{id}, a.EmitProgress)
+}
+
+// RunQueue encodes every queued job, including those left over from a previous session
+func (a *App) RunQueue() (*encoder.BatchResult, error) {
+	return a.encoder.RunJobs(a.queue, a.queue.Pending(), a.EmitProgress)
+}
+
+// ListWatchFolders returns the folders watched for new files
+func (a *App) ListWatchFolders() []watch.Folder {
+	return a.watcher.Folders()
+}
+
+// AddWatchFolder watches a folder and encodes the files dropped into it with the given options
+func (a *App) AddWatchFolder(folder watch.Folder) error {
+	return a.watcher.AddFolder(folder)
+}
+
+// RemoveWatchFolder stops watching a folder
+func (a *App) RemoveWatchFolder(path string) error {
+	return a.watcher.RemoveFolder(path)
+}
+
+// CancelEncoding stops the running batch and marks the remaining files as cancelled
+func (a *App) CancelEncoding() error {
+	return a.encoder.CancelEncoding()
+}
+
+// PauseEncoding suspends the encoding of a single file, given by the path in its progress events
+func (a *App) PauseEncoding(inputPath string) error {
+	return a.encoder.PauseEncoding(inputPath)
+}
+
+// ResumeEncoding continues a paused file
+func (a *App) ResumeEncoding(inputPath string) error {
+	return a.encoder.ResumeEncoding(inputPath)
 }
 
 func (a *App) ShowNotification(title, message string) error {
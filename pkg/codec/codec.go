@@ -1,147 +1,141 @@
 // pkg/codec/codec.go
 package codec
 
-import (
-	"context"
-	"fmt"
-	"os/exec"
-	"runtime"
-	"strings"
-	"time"
-)
+import "runtime"
 
 type CodecInfo struct {
 	Name        string   `json:"name"`        // 코덱 이름 (예: h264, hevc 등)
 	DisplayName string   `json:"displayName"` // 표시용 이름 (예: "H.264 (CPU)")
 	Hardware    string   `json:"hardware"`    // 하드웨어 가속 종류 (cpu, nvidia, intel, apple)
 	Formats     []string `json:"formats"`     // 지원하는 포맷 (mp4, webm 등)
+	Encoder     string   `json:"encoder"`     // 실제 사용되는 ffmpeg 인코더 (예: libx264)
+	Verified    bool     `json:"verified"`    // 테스트 인코딩 성공 여부 (CPU 코덱은 항상 true)
+	VerifyError string   `json:"verifyError,omitempty"`
+
+	// 비디오 코덱의 preset/tune/profile/level 허용 값
+	Options EncoderOptions `json:"options"`
 }
 
-// 지원되는 포맷 정의
-var SupportedFormats = map[string][]string{
-	"mp4":  {"h264", "h264_nvenc", "h264_qsv", "hevc", "hevc_nvenc", "hevc_qsv", "hevc_videotoolbox"},
-	"webm": {"vp8", "vp9"},
+// codecCandidate is a codec the app can offer when its ffmpeg encoder exists
+type codecCandidate struct {
+	info CodecInfo
+	// available reports whether the encoder can run on this OS; hardware
+	// encoders are confirmed afterwards with a test encode
+	available func() bool
 }
 
-// GetAvailable returns the list of available codecs on the system
-func GetAvailable() ([]CodecInfo, error) {
-	var codecs []CodecInfo
+func onOS(goos string) func() bool {
+	return func() bool { return runtime.GOOS == goos }
+}
 
-	// 기본 CPU 코덱 추가
-	codecs = append(codecs, []CodecInfo{
-		{
-			Name:        "h264",
-			DisplayName: "H.264 (CPU)",
-			Hardware:    "cpu",
-			Formats:     []string{"mp4"},
-		},
-		{
-			Name:        "hevc",
-			DisplayName: "HEVC (CPU)",
-			Hardware:    "cpu",
-			Formats:     []string{"mp4"},
-		},
-	}...)
+// 비디오 코덱 후보 목록 (표시 순서)
+var videoCandidates = []codecCandidate{
+	{info: CodecInfo{Name: "h264", DisplayName: "H.264 (CPU)", Hardware: "cpu", Encoder: "libx264"}},
+	{info: CodecInfo{Name: "hevc", DisplayName: "HEVC (CPU)", Hardware: "cpu", Encoder: "libx265"}},
+	{info: CodecInfo{Name: "hevc_videotoolbox", DisplayName: "HEVC (Apple Silicon/Intel)", Hardware: "apple", Encoder: "hevc_videotoolbox"}, available: onOS("darwin")},
+	{info: CodecInfo{Name: "h264_videotoolbox", DisplayName: "H.264 (Apple Silicon/Intel)", Hardware: "apple", Encoder: "h264_videotoolbox"}, available: onOS("darwin")},
+	{info: CodecInfo{Name: "hevc_nvenc", DisplayName: "HEVC (NVIDIA GPU)", Hardware: "nvidia", Encoder: "hevc_nvenc"}},
+	{info: CodecInfo{Name: "h264_nvenc", DisplayName: "H.264 (NVIDIA GPU)", Hardware: "nvidia", Encoder: "h264_nvenc"}},
+	{info: CodecInfo{Name: "hevc_qsv", DisplayName: "HEVC (Intel QuickSync)", Hardware: "intel", Encoder: "hevc_qsv"}},
+	{info: CodecInfo{Name: "h264_qsv", DisplayName: "H.264 (Intel QuickSync)", Hardware: "intel", Encoder: "h264_qsv"}},
+	{info: CodecInfo{Name: "av1", DisplayName: "AV1 (SVT-AV1)", Hardware: "cpu", Encoder: "libsvtav1"}},
+	{info: CodecInfo{Name: "libaom-av1", DisplayName: "AV1 (libaom)", Hardware: "cpu", Encoder: "libaom-av1"}},
+	{info: CodecInfo{Name: "librav1e", DisplayName: "AV1 (rav1e)", Hardware: "cpu", Encoder: "librav1e"}},
+	{info: CodecInfo{Name: "av1_nvenc", DisplayName: "AV1 (NVIDIA GPU)", Hardware: "nvidia", Encoder: "av1_nvenc"}},
+	{info: CodecInfo{Name: "av1_qsv", DisplayName: "AV1 (Intel QuickSync)", Hardware: "intel", Encoder: "av1_qsv"}},
+	{info: CodecInfo{Name: "vp8", DisplayName: "VP8", Hardware: "cpu", Encoder: "libvpx"}},
+	{info: CodecInfo{Name: "vp9", DisplayName: "VP9", Hardware: "cpu", Encoder: "libvpx-vp9"}},
+	{info: CodecInfo{Name: "prores", DisplayName: "Apple ProRes", Hardware: "cpu", Encoder: "prores_ks"}},
+	{info: CodecInfo{Name: "dnxhr", DisplayName: "Avid DNxHR", Hardware: "cpu", Encoder: "dnxhd"}},
+	{info: CodecInfo{Name: "ffv1", DisplayName: "FFV1 (Lossless)", Hardware: "cpu", Encoder: "ffv1"}},
+}
 
-	// FFmpeg 코덱 목록 확인
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// 오디오 코덱 후보 목록 (표시 순서)
+var audioCandidates = []codecCandidate{
+	{info: CodecInfo{Name: "aac", DisplayName: "AAC", Hardware: "cpu", Encoder: "aac"}},
+	{info: CodecInfo{Name: "libfdk_aac", DisplayName: "AAC (Fraunhofer FDK)", Hardware: "cpu", Encoder: "libfdk_aac"}},
+	{info: CodecInfo{Name: "libopus", DisplayName: "Opus", Hardware: "cpu", Encoder: "libopus"}},
+	{info: CodecInfo{Name: "libvorbis", DisplayName: "Vorbis", Hardware: "cpu", Encoder: "libvorbis"}},
+	{info: CodecInfo{Name: "libmp3lame", DisplayName: "MP3", Hardware: "cpu", Encoder: "libmp3lame"}},
+	{info: CodecInfo{Name: "ac3", DisplayName: "Dolby Digital (AC-3)", Hardware: "cpu", Encoder: "ac3"}},
+	{info: CodecInfo{Name: "pcm_s16le", DisplayName: "PCM 16-bit", Hardware: "cpu", Encoder: "pcm_s16le"}},
+	{info: CodecInfo{Name: "pcm_s24le", DisplayName: "PCM 24-bit", Hardware: "cpu", Encoder: "pcm_s24le"}},
+}
 
-	cmd := exec.CommandContext(ctx, "ffmpeg", "-encoders")
-	output, err := cmd.Output()
-	if err != nil {
-		return codecs, fmt.Errorf("failed to get ffmpeg encoder list (using default codecs only): %v", err)
+// VideoEncoder returns the ffmpeg encoder used for a video codec name.
+// Names that are not known codecs are returned unchanged.
+func VideoEncoder(name string) string {
+	for _, c := range videoCandidates {
+		if c.info.Name == name {
+			return c.info.Encoder
+		}
 	}
-	encoderList := string(output)
+	return name
+}
 
-	// OS별 하드웨어 가속 코덱 확인
-	switch runtime.GOOS {
-	case "darwin":
-		checkMacCodecs(&codecs, encoderList)
-	case "windows", "linux":
-		checkWindowsLinuxCodecs(&codecs, encoderList)
+// GetAvailable returns the list of video codecs whose encoders exist in the installed ffmpeg
+func GetAvailable() ([]CodecInfo, error) {
+	encoders, err := ListEncoders()
+	if err != nil {
+		return nil, err
+	}
+	codecs := filterCandidates(videoCandidates, newEncoderSet(encoders), EncoderVideo, Container.SupportsVideo)
+	verifyHardware(codecs)
+	for i := range codecs {
+		codecs[i].Options = OptionsFor(codecs[i].Name)
 	}
 
-	// VP8/VP9 코덱 확인
-	checkVPCodecs(&codecs, encoderList)
-
+	// 재인코딩 없이 컨테이너만 바꾸는 remux
+	codecs = append(codecs, CodecInfo{
+		Name:        CopyCodec,
+		DisplayName: "Copy (Remux)",
+		Hardware:    "cpu",
+		Formats:     formatsFor(CopyCodec, Container.SupportsVideo),
+		Encoder:     CopyCodec,
+		Verified:    true,
+	})
 	return codecs, nil
 }
 
-func checkMacCodecs(codecs *[]CodecInfo, encoderList string) {
-	if strings.Contains(encoderList, "hevc_videotoolbox") {
-		*codecs = append(*codecs, CodecInfo{
-			Name:        "hevc_videotoolbox",
-			DisplayName: "HEVC (Apple Silicon/Intel)",
-			Hardware:    "apple",
-			Formats:     []string{"mp4"},
-		})
+// GetAvailableAudio returns the list of audio codecs whose encoders exist in the installed ffmpeg
+func GetAvailableAudio() ([]CodecInfo, error) {
+	encoders, err := ListEncoders()
+	if err != nil {
+		return nil, err
 	}
-	if strings.Contains(encoderList, "h264_videotoolbox") {
-		*codecs = append(*codecs, CodecInfo{
-			Name:        "h264_videotoolbox",
-			DisplayName: "H.264 (Apple Silicon/Intel)",
-			Hardware:    "apple",
-			Formats:     []string{"mp4"},
-		})
+	codecs := filterCandidates(audioCandidates, newEncoderSet(encoders), EncoderAudio, Container.SupportsAudio)
+	for i := range codecs {
+		codecs[i].Verified = true
 	}
+	return codecs, nil
 }
 
-func checkWindowsLinuxCodecs(codecs *[]CodecInfo, encoderList string) {
-	if hasNvidiaGPU() {
-		if strings.Contains(encoderList, "hevc_nvenc") {
-			*codecs = append(*codecs, CodecInfo{
-				Name:        "hevc_nvenc",
-				DisplayName: "HEVC (NVIDIA GPU)",
-				Hardware:    "nvidia",
-				Formats:     []string{"mp4"},
-			})
+// filterCandidates keeps the candidates whose encoder is present and whose
+// hardware is available, filling in the formats each codec can be used with
+func filterCandidates(candidates []codecCandidate, encoders encoderSet, encoderType EncoderType, supports func(Container, string) bool) []CodecInfo {
+	var codecs []CodecInfo
+	for _, c := range candidates {
+		if !encoders.has(c.info.Encoder, encoderType) {
+			continue
 		}
-		if strings.Contains(encoderList, "h264_nvenc") {
-			*codecs = append(*codecs, CodecInfo{
-				Name:        "h264_nvenc",
-				DisplayName: "H.264 (NVIDIA GPU)",
-				Hardware:    "nvidia",
-				Formats:     []string{"mp4"},
-			})
+		if c.available != nil && !c.available() {
+			continue
 		}
-	}
 
-	if hasIntelGPU() {
-		if strings.Contains(encoderList, "hevc_qsv") {
-			*codecs = append(*codecs, CodecInfo{
-				Name:        "hevc_qsv",
-				DisplayName: "HEVC (Intel QuickSync)",
-				Hardware:    "intel",
-				Formats:     []string{"mp4"},
-			})
-		}
-		if strings.Contains(encoderList, "h264_qsv") {
-			*codecs = append(*codecs, CodecInfo{
-				Name:        "h264_qsv",
-				DisplayName: "H.264 (Intel QuickSync)",
-				Hardware:    "intel",
-				Formats:     []string{"mp4"},
-			})
-		}
+		info := c.info
+		info.Formats = formatsFor(info.Name, supports)
+		codecs = append(codecs, info)
 	}
+	return codecs
 }
 
-func checkVPCodecs(codecs *[]CodecInfo, encoderList string) {
-	if strings.Contains(encoderList, "libvpx") {
-		*codecs = append(*codecs, CodecInfo{
-			Name:        "vp8",
-			DisplayName: "VP8",
-			Hardware:    "cpu",
-			Formats:     []string{"webm"},
-		})
-	}
-	if strings.Contains(encoderList, "libvpx-vp9") {
-		*codecs = append(*codecs, CodecInfo{
-			Name:        "vp9",
-			DisplayName: "VP9",
-			Hardware:    "cpu",
-			Formats:     []string{"webm"},
-		})
+// formatsFor returns the container formats that accept the codec, in table order
+func formatsFor(name string, supports func(Container, string) bool) []string {
+	var result []string
+	for _, c := range Containers {
+		if supports(c, name) {
+			result = append(result, c.Format)
+		}
 	}
+	return result
 }
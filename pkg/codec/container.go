@@ -0,0 +1,147 @@
+// pkg/codec/container.go
+package codec
+
+import (
+	"fmt"
+	"slices"
+)
+
+// CopyCodec is the video/audio codec name that copies streams without re-encoding
+const CopyCodec = "copy"
+
+// Container is an output container format and the codecs it can hold
+type Container struct {
+	Format      string   `json:"format"` // EncodingOptions.VideoFormat 값
+	DisplayName string   `json:"displayName"`
+	Extension   string   `json:"extension"` // 출력 파일 확장자 (점 제외)
+	VideoCodecs []string `json:"videoCodecs"`
+	AudioCodecs []string `json:"audioCodecs"`
+
+	// 그대로 복사할 수 있는 자막 코덱 (ffprobe 코덱 이름)
+	SubtitleCodecs []string `json:"subtitleCodecs"`
+	// 복사할 수 없는 텍스트 자막을 변환할 인코더 (비어 있으면 제외)
+	TextSubtitleEncoder string `json:"textSubtitleEncoder"`
+	// 글꼴 등 첨부 파일 보존 여부
+	Attachments bool `json:"attachments"`
+}
+
+var (
+	h264Codecs = []string{"h264", "h264_nvenc", "h264_qsv", "h264_videotoolbox"}
+	hevcCodecs = []string{"hevc", "hevc_nvenc", "hevc_qsv", "hevc_videotoolbox"}
+	av1Codecs  = []string{"av1", "libaom-av1", "librav1e", "av1_nvenc", "av1_qsv"}
+
+	// 서로 변환할 수 있는 텍스트 자막 (PGS, DVD 등 이미지 자막은 변환 불가)
+	textSubtitleCodecs = []string{"subrip", "ass", "ssa", "mov_text", "webvtt", "text"}
+)
+
+// 컨테이너/코덱 호환성 표 (표시 순서)
+// copy는 모든 컨테이너에서 허용되며, 원본 코덱을 담을 수 없는 경우 ffmpeg가 실패함
+var Containers = []Container{
+	{
+		Format:      "mp4",
+		DisplayName: "MP4",
+		Extension:   "mp4",
+		VideoCodecs: concat(h264Codecs, hevcCodecs, av1Codecs),
+		AudioCodecs: []string{"aac", "libfdk_aac", "libmp3lame", "ac3", "libopus"},
+
+		SubtitleCodecs:      []string{"mov_text"},
+		TextSubtitleEncoder: "mov_text",
+	},
+	{
+		Format:      "webm",
+		DisplayName: "WebM",
+		Extension:   "webm",
+		VideoCodecs: concat([]string{"vp8", "vp9"}, av1Codecs),
+		AudioCodecs: []string{"libopus", "libvorbis"},
+
+		SubtitleCodecs:      []string{"webvtt"},
+		TextSubtitleEncoder: "webvtt",
+	},
+	{
+		Format:      "mkv",
+		DisplayName: "Matroska (MKV)",
+		Extension:   "mkv",
+		VideoCodecs: concat(h264Codecs, hevcCodecs, av1Codecs, []string{"vp8", "vp9", "prores", "dnxhr", "ffv1"}),
+		AudioCodecs: []string{"aac", "libfdk_aac", "libmp3lame", "ac3", "libopus", "libvorbis", "pcm_s16le", "pcm_s24le"},
+
+		SubtitleCodecs:      []string{"subrip", "ass", "ssa", "webvtt", "hdmv_pgs_subtitle", "dvd_subtitle", "dvb_subtitle"},
+		TextSubtitleEncoder: "srt",
+		Attachments:         true,
+	},
+	{
+		Format:      "mov",
+		DisplayName: "QuickTime (MOV)",
+		Extension:   "mov",
+		VideoCodecs: concat(h264Codecs, hevcCodecs, []string{"prores", "dnxhr"}),
+		AudioCodecs: []string{"aac", "libfdk_aac", "libmp3lame", "ac3", "pcm_s16le", "pcm_s24le"},
+
+		SubtitleCodecs:      []string{"mov_text"},
+		TextSubtitleEncoder: "mov_text",
+	},
+	{
+		Format:      "mpegts",
+		DisplayName: "MPEG-TS",
+		Extension:   "ts",
+		VideoCodecs: concat(h264Codecs, hevcCodecs),
+		AudioCodecs: []string{"aac", "libmp3lame", "ac3", "libopus"},
+
+		SubtitleCodecs: []string{"dvb_subtitle"},
+	},
+}
+
+func concat(lists ...[]string) []string {
+	var result []string
+	for _, list := range lists {
+		result = append(result, list...)
+	}
+	return result
+}
+
+// LookupContainer returns the container with the given format name
+func LookupContainer(format string) (Container, bool) {
+	for _, c := range Containers {
+		if c.Format == format {
+			return c, true
+		}
+	}
+	return Container{}, false
+}
+
+// SupportsVideo reports whether the container can hold the video codec
+func (c Container) SupportsVideo(name string) bool {
+	return name == CopyCodec || slices.Contains(c.VideoCodecs, name)
+}
+
+// SupportsAudio reports whether the container can hold the audio codec
+func (c Container) SupportsAudio(name string) bool {
+	return name == CopyCodec || slices.Contains(c.AudioCodecs, name)
+}
+
+// SubtitleEncoder returns how a subtitle stream of the given codec is written
+// to the container: "copy", the encoder that converts it, or "" if the
+// container cannot hold it and the stream is dropped
+func (c Container) SubtitleEncoder(name string) string {
+	switch {
+	case slices.Contains(c.SubtitleCodecs, name):
+		return CopyCodec
+	case c.TextSubtitleEncoder != "" && slices.Contains(textSubtitleCodecs, name):
+		return c.TextSubtitleEncoder
+	}
+	return ""
+}
+
+// CheckCompatibility returns an error if the container cannot hold the video
+// or audio codec. An empty audio codec means the default and is always accepted.
+func CheckCompatibility(format, videoCodec, audioCodec string) error {
+	c, ok := LookupContainer(format)
+	if !ok {
+		return fmt.Errorf("unsupported video format: %s", format)
+	}
+	if !c.SupportsVideo(videoCodec) {
+		return fmt.Errorf("unsupported codec %s for format %s", videoCodec, format)
+	}
+	if audioCodec != "" && !c.SupportsAudio(audioCodec) {
+		return fmt.Errorf("unsupported audio codec %s for format %s", audioCodec, format)
+	}
+	return nil
+}
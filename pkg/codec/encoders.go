@@ -0,0 +1,108 @@
+// pkg/codec/encoders.go
+package codec
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"encoder/pkg/ffmpeg"
+)
+
+type EncoderType string
+
+const (
+	EncoderVideo    EncoderType = "video"
+	EncoderAudio    EncoderType = "audio"
+	EncoderSubtitle EncoderType = "subtitle"
+)
+
+// Encoder is a single row of the `ffmpeg -encoders` table
+type Encoder struct {
+	Type        EncoderType `json:"type"`
+	Flags       string      `json:"flags"` // 예: V....D (F: 프레임 멀티스레드, S: 슬라이스 멀티스레드, X: 실험적)
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+}
+
+// Experimental reports whether ffmpeg marks the encoder as experimental
+func (e Encoder) Experimental() bool {
+	return len(e.Flags) > 3 && e.Flags[3] == 'X'
+}
+
+// ListEncoders runs `ffmpeg -encoders` and returns every encoder it lists
+func ListEncoders() ([]Encoder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cmd, err := ffmpeg.Command(ctx, "-hide_banner", "-encoders")
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ffmpeg encoder list: %w", err)
+	}
+
+	return parseEncoders(string(output)), nil
+}
+
+// parseEncoders parses the encoder table that follows the " ------" separator
+func parseEncoders(output string) []Encoder {
+	var encoders []Encoder
+	inTable := false
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !inTable {
+			inTable = strings.HasPrefix(line, "---")
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields[0]) < 6 {
+			continue
+		}
+
+		var encoderType EncoderType
+		switch fields[0][0] {
+		case 'V':
+			encoderType = EncoderVideo
+		case 'A':
+			encoderType = EncoderAudio
+		case 'S':
+			encoderType = EncoderSubtitle
+		default:
+			continue
+		}
+
+		encoders = append(encoders, Encoder{
+			Type:        encoderType,
+			Flags:       fields[0],
+			Name:        fields[1],
+			Description: strings.Join(fields[2:], " "),
+		})
+	}
+
+	return encoders
+}
+
+// encoderSet indexes encoders by name
+type encoderSet map[string]Encoder
+
+func newEncoderSet(encoders []Encoder) encoderSet {
+	set := make(encoderSet, len(encoders))
+	for _, e := range encoders {
+		set[e.Name] = e
+	}
+	return set
+}
+
+// has reports whether an encoder of the given type and exact name exists
+func (s encoderSet) has(name string, encoderType EncoderType) bool {
+	e, ok := s[name]
+	return ok && e.Type == encoderType
+}
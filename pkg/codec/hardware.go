@@ -3,57 +3,208 @@ package codec
 
 import (
 	"context"
-	"os/exec"
-	"runtime"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"encoder/pkg/ffmpeg"
+	"encoder/pkg/fileutil"
 )
 
-// NVIDIA GPU 존재 여부 확인
-func hasNvidiaGPU() bool {
-	// 명령어 실행 시 타임아웃 설정
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
+// verifyTimeout bounds a single hardware encoder test encode
+const verifyTimeout = 10 * time.Second
+
+// failedVerifyTTL is how long a failed test encode is trusted. Failures can be
+// temporary (all NVENC sessions busy, driver installed later), so unlike
+// successes they are tested again after a while.
+const failedVerifyTTL = 15 * time.Minute
+
+// verifyResult is the outcome of a test encode
+type verifyResult struct {
+	OK        bool      `json:"ok"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedat"`
+}
+
+// fresh reports whether the result can be used without testing again
+func (r verifyResult) fresh() bool {
+	return r.OK || time.Since(r.CheckedAt) < failedVerifyTTL
+}
+
+// verifyCache stores test encode results for one ffmpeg build
+type verifyCache struct {
+	Build   string                  `json:"build"`
+	Results map[string]verifyResult `json:"results"`
+}
+
+var verifyMu sync.Mutex
+
+// verifyHardware runs a short test encode for every hardware codec and
+// marks it verified or records why it failed. Results are cached on disk per
+// ffmpeg build; successes are kept until ffmpeg changes and failures for
+// failedVerifyTTL.
+func verifyHardware(codecs []CodecInfo) {
+	verifyMu.Lock()
+	defer verifyMu.Unlock()
+
+	// CPU 코덱은 테스트하지 않으며 항상 사용 가능
+	for i := range codecs {
+		if codecs[i].Hardware == "cpu" {
+			codecs[i].Verified = true
+		}
+	}
+
+	build, err := ffmpegBuild()
+	if err != nil {
+		for i := range codecs {
+			if codecs[i].Hardware != "cpu" {
+				codecs[i].VerifyError = err.Error()
+			}
+		}
+		return
+	}
+
+	cachePath := verifyCachePath()
+	cache := loadVerifyCache(cachePath)
+	if cache.Build != build {
+		cache = verifyCache{Build: build}
+	}
+	if cache.Results == nil {
+		cache.Results = map[string]verifyResult{}
+	}
+
+	// 캐시에 없는 인코더만 병렬로 테스트
+	var wg sync.WaitGroup
+	var resultsMu sync.Mutex
+	for _, c := range codecs {
+		if c.Hardware == "cpu" {
+			continue
+		}
+		if result, ok := cache.Results[c.Encoder]; ok && result.fresh() {
+			continue
+		}
+
+		wg.Add(1)
+		go func(encoder string) {
+			defer wg.Done()
+			result := verifyResult{OK: true, CheckedAt: time.Now()}
+			if err := testEncode(encoder); err != nil {
+				result = verifyResult{Error: err.Error(), CheckedAt: time.Now()}
+			}
+			resultsMu.Lock()
+			cache.Results[encoder] = result
+			resultsMu.Unlock()
+		}(c.Encoder)
+	}
+	wg.Wait()
+
+	saveVerifyCache(cachePath, cache)
 
-	switch runtime.GOOS {
-	case "windows":
-		cmd := exec.CommandContext(ctx, "nvidia-smi")
-		return cmd.Run() == nil
-	case "linux":
-		cmd := exec.CommandContext(ctx, "lspci")
-		output, err := cmd.Output()
-		if err != nil {
-			return false
+	for i := range codecs {
+		if codecs[i].Hardware == "cpu" {
+			continue
 		}
-		return strings.Contains(strings.ToLower(string(output)), "nvidia")
+		result := cache.Results[codecs[i].Encoder]
+		codecs[i].Verified = result.OK
+		codecs[i].VerifyError = result.Error
 	}
-	return false
 }
 
-// Intel GPU 존재 여부 확인
-func hasIntelGPU() bool {
-	// 명령어 실행 시 타임아웃 설정
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+// ReverifyHardware discards the cached test encode results and returns the
+// available codecs with every hardware encoder tested again
+func ReverifyHardware() ([]CodecInfo, error) {
+	verifyMu.Lock()
+	if path := verifyCachePath(); path != "" {
+		os.Remove(path)
+	}
+	verifyMu.Unlock()
+
+	return GetAvailable()
+}
+
+// testEncode encodes a few frames of a generated test pattern with encoder
+func testEncode(encoder string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
 	defer cancel()
 
-	switch runtime.GOOS {
-	case "windows":
-		// Windows의 경우 wmic로 Intel Graphics 확인
-		cmd := exec.CommandContext(ctx, "wmic", "path", "win32_VideoController", "get", "name")
-		output, err := cmd.Output()
-		if err != nil {
-			return false
-		}
-		return strings.Contains(strings.ToLower(string(output)), "intel") &&
-			strings.Contains(strings.ToLower(string(output)), "graphics")
-	case "linux":
-		cmd := exec.CommandContext(ctx, "lspci")
-		output, err := cmd.Output()
-		if err != nil {
-			return false
+	cmd, err := ffmpeg.Command(ctx,
+		"-hide_banner",
+		"-v", "error",
+		"-f", "lavfi",
+		"-i", "testsrc=size=256x256:rate=30",
+		"-frames:v", "5",
+		"-c:v", encoder,
+		"-f", "null",
+		"-",
+	)
+	if err != nil {
+		return err
+	}
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("test encode timed out after %s", verifyTimeout)
+	}
+	if err != nil {
+		// ffmpeg 오류 메시지의 마지막 줄이 가장 구체적인 원인
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		if reason := strings.TrimSpace(lines[len(lines)-1]); reason != "" {
+			return errors.New(reason)
 		}
-		return strings.Contains(strings.ToLower(string(output)), "intel") &&
-			strings.Contains(strings.ToLower(string(output)), "graphics")
+		return fmt.Errorf("test encode failed: %w", err)
+	}
+	return nil
+}
+
+// ffmpegBuild identifies the ffmpeg binary so cached results are dropped when it changes
+func ffmpegBuild() (string, error) {
+	info, err := ffmpeg.GetInfo()
+	if err != nil {
+		return "", err
+	}
+
+	build := info.Path + "|" + info.VersionString
+	if stat, err := os.Stat(info.Path); err == nil {
+		build += "|" + stat.ModTime().UTC().Format(time.RFC3339)
+	}
+	return build, nil
+}
+
+func verifyCachePath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "encoder", "hardware-encoders.json")
+}
+
+func loadVerifyCache(path string) verifyCache {
+	var cache verifyCache
+	if path == "" {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return verifyCache{}
+	}
+	return cache
+}
+
+func saveVerifyCache(path string, cache verifyCache) {
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
 	}
-	return false
+	fileutil.WriteFile(path, data)
 }
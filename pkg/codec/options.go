@@ -0,0 +1,232 @@
+// pkg/codec/options.go
+package codec
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"encoder/pkg/ffmpeg"
+)
+
+// EncoderOptions lists the preset, tune, profile and level values a video codec accepts.
+// An empty list means the codec does not take the option.
+type EncoderOptions struct {
+	Presets  []string `json:"presets"`
+	Tunes    []string `json:"tunes"`
+	Profiles []string `json:"profiles"`
+	Levels   []string `json:"levels"`
+}
+
+var (
+	x264Presets  = []string{"ultrafast", "superfast", "veryfast", "faster", "fast", "medium", "slow", "slower", "veryslow", "placebo"}
+	nvencPresets = []string{"p1", "p2", "p3", "p4", "p5", "p6", "p7"}
+	nvencTunes   = []string{"hq", "ll", "ull", "lossless"}
+	qsvPresets   = []string{"veryfast", "faster", "fast", "medium", "slow", "slower", "veryslow"}
+	h264Levels   = []string{"1", "1b", "1.1", "1.2", "1.3", "2", "2.1", "2.2", "3", "3.1", "3.2", "4", "4.1", "4.2", "5", "5.1", "5.2", "6", "6.1", "6.2"}
+	hevcLevels   = []string{"1", "2", "2.1", "3", "3.1", "4", "4.1", "5", "5.1", "5.2", "6", "6.1", "6.2"}
+)
+
+// 코덱별 기본 옵션 값 (ffmpeg -h encoder=<name>으로 값을 알 수 없을 때 사용)
+// libx264/libx265는 preset/tune/profile이 문자열 옵션이라 목록을 출력하지 않음
+var staticEncoderOptions = map[string]EncoderOptions{
+	"h264": {
+		Presets:  x264Presets,
+		Tunes:    []string{"film", "animation", "grain", "stillimage", "fastdecode", "zerolatency", "psnr", "ssim"},
+		Profiles: []string{"baseline", "main", "high", "high10", "high422", "high444"},
+		Levels:   h264Levels,
+	},
+	"hevc": {
+		Presets:  x264Presets,
+		Tunes:    []string{"animation", "grain", "fastdecode", "zerolatency", "psnr", "ssim"},
+		Profiles: []string{"main", "main10", "mainstillpicture", "main422-10", "main444-8", "main444-10"},
+		Levels:   hevcLevels,
+	},
+	"h264_nvenc": {
+		Presets:  nvencPresets,
+		Tunes:    nvencTunes,
+		Profiles: []string{"baseline", "main", "high", "high444p"},
+		Levels:   h264Levels,
+	},
+	"hevc_nvenc": {
+		Presets:  nvencPresets,
+		Tunes:    nvencTunes,
+		Profiles: []string{"main", "main10", "rext"},
+		Levels:   hevcLevels,
+	},
+	"av1_nvenc": {
+		Presets:  nvencPresets,
+		Tunes:    nvencTunes,
+		Profiles: []string{"main"},
+	},
+	"h264_qsv": {
+		Presets:  qsvPresets,
+		Profiles: []string{"baseline", "main", "high"},
+		Levels:   h264Levels,
+	},
+	"hevc_qsv": {
+		Presets:  qsvPresets,
+		Profiles: []string{"main", "main10", "mainsp", "rext"},
+		Levels:   hevcLevels,
+	},
+	"av1_qsv": {
+		Presets:  qsvPresets,
+		Profiles: []string{"main"},
+	},
+	"h264_videotoolbox": {
+		Profiles: []string{"baseline", "main", "high"},
+	},
+	"hevc_videotoolbox": {
+		Profiles: []string{"main", "main10"},
+	},
+	"vp9": {
+		Profiles: []string{"0", "1", "2", "3"},
+	},
+	"av1": { // libsvtav1 -preset
+		Presets: []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13"},
+	},
+	"libaom-av1": { // -cpu-used
+		Presets: []string{"0", "1", "2", "3", "4", "5", "6", "7", "8"},
+		Tunes:   []string{"psnr", "ssim"},
+	},
+	"librav1e": { // -speed
+		Presets: []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10"},
+	},
+	"prores": {
+		Profiles: []string{"proxy", "lt", "standard", "hq", "4444"},
+	},
+	"dnxhr": {
+		Profiles: []string{"dnxhr_lb", "dnxhr_sq", "dnxhr_hq", "dnxhr_hqx", "dnxhr_444"},
+	},
+}
+
+// 정적 목록을 그대로 사용하는 코덱: Presets가 -preset 이외의 속도 옵션에 대응하거나
+// (libaom-av1, librav1e) 프로파일마다 픽셀 포맷이 정해져 있음 (prores, dnxhr)
+var fixedEncoderOptions = map[string]bool{
+	"libaom-av1": true,
+	"librav1e":   true,
+	"prores":     true,
+	"dnxhr":      true,
+}
+
+// introspectedOptions caches the parsed `ffmpeg -h encoder=<name>` output per ffmpeg build and encoder
+var (
+	introspectedMu      sync.Mutex
+	introspectedOptions = map[string]EncoderOptions{}
+)
+
+// OptionsFor returns the preset, tune, profile and level values of a video codec.
+// Values ffmpeg lists for the encoder replace the built-in ones; the built-in
+// values are used when ffmpeg is unavailable or only takes a free-form string.
+func OptionsFor(name string) EncoderOptions {
+	options := staticEncoderOptions[name]
+
+	encoder := VideoEncoder(name)
+	if encoder == CopyCodec || fixedEncoderOptions[name] {
+		return options
+	}
+
+	listed, err := introspect(encoder)
+	if err != nil {
+		return options
+	}
+	if len(listed.Presets) > 0 {
+		options.Presets = listed.Presets
+	}
+	if len(listed.Tunes) > 0 {
+		options.Tunes = listed.Tunes
+	}
+	if len(listed.Profiles) > 0 {
+		options.Profiles = listed.Profiles
+	}
+	if len(listed.Levels) > 0 {
+		options.Levels = listed.Levels
+	}
+	return options
+}
+
+// introspect runs `ffmpeg -h encoder=<name>` once per ffmpeg build and encoder
+func introspect(encoder string) (EncoderOptions, error) {
+	build, err := ffmpegBuild()
+	if err != nil {
+		return EncoderOptions{}, err
+	}
+	key := build + "|" + encoder
+
+	introspectedMu.Lock()
+	options, ok := introspectedOptions[key]
+	introspectedMu.Unlock()
+	if ok {
+		return options, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cmd, err := ffmpeg.Command(ctx, "-hide_banner", "-h", "encoder="+encoder)
+	if err != nil {
+		return EncoderOptions{}, err
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return EncoderOptions{}, err
+	}
+
+	options = parseEncoderHelp(string(output))
+
+	introspectedMu.Lock()
+	introspectedOptions[key] = options
+	introspectedMu.Unlock()
+	return options, nil
+}
+
+// parseEncoderHelp collects the named constants listed under the preset, tune,
+// profile and level AVOptions of `ffmpeg -h encoder=<name>`:
+//
+//	-preset            <int>        E..V....... Set the encoding preset (from 0 to 18) (default p4)
+//	   default         0            E..V.......
+//	   p1              12           E..V....... fastest (lowest quality)
+func parseEncoderHelp(output string) EncoderOptions {
+	var options EncoderOptions
+	var current *[]string
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			current = nil
+			continue
+		}
+
+		// 옵션 줄은 "  -이름", 상수 줄은 더 깊게 들여쓰기됨
+		if strings.HasPrefix(trimmed, "-") {
+			current = nil
+			switch strings.Fields(trimmed)[0] {
+			case "-preset":
+				current = &options.Presets
+			case "-tune":
+				current = &options.Tunes
+			case "-profile":
+				current = &options.Profiles
+			case "-level":
+				current = &options.Levels
+			}
+			continue
+		}
+		if current == nil || !strings.HasPrefix(line, "     ") {
+			current = nil
+			continue
+		}
+
+		name := strings.Fields(trimmed)[0]
+		// default/auto는 옵션을 지정하지 않은 것과 같음
+		if name == "default" || name == "auto" {
+			continue
+		}
+		*current = append(*current, name)
+	}
+	return options
+}
@@ -3,150 +3,533 @@ package encoder
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"encoder/pkg/ffmpeg"
+)
+
+var (
+	// ErrCancelled is returned when a running batch is cancelled by the user
+	ErrCancelled = errors.New("encoding cancelled")
+	// ErrOutputExists is returned when the output path is already taken
+	ErrOutputExists = errors.New("output file already exists")
+	// ErrBatchStopped is recorded for files not started because an earlier file failed
+	ErrBatchStopped = errors.New("not started because an earlier file failed")
+	// ErrBusy is returned when a batch is started while another one is running
+	ErrBusy = errors.New("encoding is already in progress")
+	// ErrJobChanged is reported for queue jobs removed or started elsewhere before their turn
+	ErrJobChanged = errors.New("job was removed or changed in the queue before it started")
 )
 
+// passLogSeq keeps 2-pass log names unique between parallel workers
+var passLogSeq atomic.Int64
+
 type Encoder struct {
 	ctx context.Context
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	jobs    map[string]*activeJob // 입력 파일 경로별 실행 중인 작업
+	outputs map[string]bool       // 실행 중인 작업이 쓰고 있는 출력 경로 (pathKey 기준)
+}
+
+// activeJob tracks the ffmpeg process currently running for a file
+type activeJob struct {
+	cmd    *exec.Cmd
+	reader *ProgressReader
+	paused bool
 }
 
 func NewEncoder(ctx context.Context) *Encoder {
 	return &Encoder{
-		ctx: ctx,
+		ctx:     ctx,
+		jobs:    make(map[string]*activeJob),
+		outputs: make(map[string]bool),
 	}
 }
 
-// StartEncoding starts the encoding process for multiple files
-func (e *Encoder) StartEncoding(paths []string, options EncodingOptions, progressCallback func(EncodingProgress)) error {
+// StartEncoding starts the encoding process for multiple files. An error is
+// only returned if the batch could not start; per-file failures and
+// cancellation are reported in the BatchResult.
+func (e *Encoder) StartEncoding(paths []string, options EncodingOptions, progressCallback func(EncodingProgress)) (*BatchResult, error) {
 	if err := options.Validate(); err != nil {
-		return fmt.Errorf("invalid encoding options: %w", err)
+		return nil, fmt.Errorf("invalid encoding options: %w", err)
+	}
+
+	// FFmpeg 존재 여부 및 버전 확인
+	if _, err := ffmpeg.Check(); err != nil {
+		return nil, err
+	}
+
+	tasks, err := newEncodeTasks(paths, options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := e.beginBatch()
+	if err != nil {
+		return nil, err
+	}
+	defer e.endBatch()
+
+	return e.runPool(ctx, tasks, options.concurrency(), progressCallback), nil
+}
+
+// RunJobs encodes the given queue jobs in queue order, recording each result
+// in the queue. The worker count is taken from the first runnable job's
+// options. Jobs whose options are invalid are reported as failed. Like
+// StartEncoding, it only returns an error if the batch could not start.
+func (e *Encoder) RunJobs(q *Queue, ids []string, progressCallback func(EncodingProgress)) (*BatchResult, error) {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var tasks []encodeTask
+	for _, job := range q.List() {
+		if !wanted[job.ID] || job.Status != JobQueued {
+			continue
+		}
+
+		id := job.ID
+		task := encodeTask{
+			inputPath:  job.InputPath,
+			outputPath: job.OutputPath,
+			options:    job.Options,
+			started:    func() bool { return q.start(id) != nil },
+			resolved:   func(outputPath string) { q.setOutput(id, outputPath) },
+			finished:   func(status JobStatus, err error) { q.finish(id, status, err) },
+		}
+
+		// 실행할 수 없는 작업은 인코딩하지 않고 실패로 보고
+		if err := task.options.Validate(); err != nil {
+			task.err = fmt.Errorf("invalid encoding options: %w", err)
+		} else if task.outputPath == "" {
+			outputs, err := task.options.OutputPaths([]string{job.InputPath})
+			if err != nil {
+				task.err = err
+			} else {
+				task.outputPath = outputs[0]
+			}
+		}
+		tasks = append(tasks, task)
+	}
+
+	workers := 0
+	for _, task := range tasks {
+		if task.err == nil {
+			workers = task.options.concurrency()
+			break
+		}
+	}
+	if workers == 0 {
+		return e.runPool(context.Background(), tasks, 0, progressCallback), nil
+	}
+
+	// FFmpeg 존재 여부 및 버전 확인
+	if _, err := ffmpeg.Check(); err != nil {
+		return nil, err
+	}
+
+	ctx, err := e.beginBatch()
+	if err != nil {
+		return nil, err
+	}
+	defer e.endBatch()
+
+	return e.runPool(ctx, tasks, workers, progressCallback), nil
+}
+
+// Running reports whether a batch is in progress
+func (e *Encoder) Running() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.cancel != nil
+}
+
+// CancelEncoding stops the running batch, killing the current ffmpeg process
+// and skipping every file that has not been started yet
+func (e *Encoder) CancelEncoding() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cancel == nil {
+		return fmt.Errorf("no encoding in progress")
 	}
+	e.cancel()
+	return nil
+}
 
-	// FFmpeg 존재 여부 확인
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		return fmt.Errorf("FFmpeg is not installed: %w", err)
+// beginBatch registers a new cancellable batch
+func (e *Encoder) beginBatch() (context.Context, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cancel != nil {
+		return nil, ErrBusy
 	}
 
-	for _, inputPath := range paths {
-		if err := e.encodeFile(inputPath, options, progressCallback); err != nil {
-			return err
+	parent := e.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	e.cancel = cancel
+	return ctx, nil
+}
+
+// PauseEncoding suspends the ffmpeg process encoding the given input file.
+// inputPath is the full path reported in EncodingProgress.Path.
+func (e *Encoder) PauseEncoding(inputPath string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	job, ok := e.jobs[inputPath]
+	if !ok {
+		return fmt.Errorf("no encoding in progress for %s", inputPath)
+	}
+	if job.paused {
+		return nil
+	}
+
+	// 패스 사이에는 실행 중인 프로세스가 없으므로 다음 패스 시작 시 정지
+	if job.cmd != nil && job.cmd.Process != nil {
+		if err := suspendProcess(job.cmd.Process); err != nil {
+			return fmt.Errorf("failed to pause encoding (%s): %w", inputPath, err)
 		}
 	}
+	job.paused = true
+	if job.reader != nil {
+		job.reader.SetPaused(true)
+	}
+	return nil
+}
+
+// ResumeEncoding continues a file previously paused with PauseEncoding
+func (e *Encoder) ResumeEncoding(inputPath string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
+	job, ok := e.jobs[inputPath]
+	if !ok {
+		return fmt.Errorf("no encoding in progress for %s", inputPath)
+	}
+	if !job.paused {
+		return nil
+	}
+
+	if job.cmd != nil && job.cmd.Process != nil {
+		if err := resumeProcess(job.cmd.Process); err != nil {
+			return fmt.Errorf("failed to resume encoding (%s): %w", inputPath, err)
+		}
+	}
+	job.paused = false
+	if job.reader != nil {
+		job.reader.SetPaused(false)
+	}
 	return nil
 }
 
-// encodeFile handles the encoding of a single file
-func (e *Encoder) encodeFile(inputPath string, options EncodingOptions, progressCallback func(EncodingProgress)) error {
+func (e *Encoder) endBatch() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+}
+
+// encodeFile handles the encoding of a single file and reports its final status
+func (e *Encoder) encodeFile(ctx context.Context, task encodeTask, progressCallback func(EncodingProgress)) error {
+	inputPath, options := task.inputPath, task.options
+	filename := filepath.Base(inputPath)
+
+	// 같은 이름의 파일이 동시에 인코딩될 수 있으므로 모든 진행 상황에 전체 경로 포함
+	callback := progressCallback
+	progressCallback = func(progress EncodingProgress) {
+		progress.Path = inputPath
+		callback(progress)
+	}
+
+	err := e.encode(ctx, task, progressCallback)
+	switch {
+	case err == nil:
+		// 완료 상태 업데이트
+		progressCallback(EncodingProgress{
+			Filename: filename,
+			Status:   "completed",
+			Progress: 100,
+		})
+	case errors.Is(err, ErrCancelled):
+		progressCallback(EncodingProgress{
+			Filename: filename,
+			Status:   "cancelled",
+		})
+	case options.skips(err):
+		progressCallback(EncodingProgress{
+			Filename: filename,
+			Status:   "skipped",
+			Error:    err.Error(),
+		})
+	default:
+		progress := EncodingProgress{
+			Filename: filename,
+			Status:   "failed",
+			Error:    err.Error(),
+		}
+		errors.As(err, &progress.ErrorDetails)
+		progressCallback(progress)
+	}
+
+	return err
+}
+
+// encode runs ffmpeg for a single file
+func (e *Encoder) encode(ctx context.Context, task encodeTask, progressCallback func(EncodingProgress)) error {
+	inputPath, outputPath, options := task.inputPath, task.outputPath, task.options
+
+	// 워커가 파일을 맡은 직후 취소된 경우
+	if ctx.Err() != nil {
+		return ErrCancelled
+	}
+
 	// 입력 파일 존재 여부 확인
 	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
 		return fmt.Errorf("input file not found (%s): %w", inputPath, err)
 	}
 
-	filename := filepath.Base(inputPath)
-	outputPath := options.getOutputPath(inputPath)
-
 	// 출력 디렉토리 생성
 	outputDir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory (%s): %w", outputDir, err)
 	}
 
-	// 출력 파일 중복 확인
-	if _, err := os.Stat(outputPath); err == nil {
-		return fmt.Errorf("output file already exists: %s", outputPath)
+	// 출력 파일 중복 확인 (다른 워커가 같은 이름을 고르지 않도록 예약)
+	outputPath, release, err := e.reserveOutput(options, outputPath)
+	if err != nil {
+		return err
 	}
+	defer release()
+	if task.resolved != nil {
+		task.resolved(outputPath)
+	}
+
+	// ffmpeg는 임시 파일에 쓰고, 정상 종료 후 최종 경로로 이동
+	tempPath := partialPath(outputPath)
+	os.Remove(tempPath)
+
+	e.mu.Lock()
+	e.jobs[inputPath] = &activeJob{}
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		delete(e.jobs, inputPath)
+		e.mu.Unlock()
+	}()
 
 	// 초기 진행상황 알림
 	progressCallback(EncodingProgress{
-		Filename: filename,
+		Filename: filepath.Base(inputPath),
 		Status:   "processing",
 	})
 
 	if options.Use2Pass && options.QualityMode == QualityModeBitrate {
-		if err := e.runTwoPassEncoding(inputPath, outputPath, options, progressCallback); err != nil {
-			return err
-		}
+		err = e.runTwoPassEncoding(ctx, inputPath, tempPath, options, progressCallback)
 	} else {
-		if err := e.runSinglePassEncoding(inputPath, outputPath, options, progressCallback); err != nil {
-			return err
+		err = e.runSinglePassEncoding(ctx, inputPath, tempPath, options, progressCallback)
+	}
+	if err != nil {
+		// 중단되거나 실패한 출력 파일 정리
+		os.Remove(tempPath)
+		var encErr *EncodeError
+		if errors.As(err, &encErr) {
+			encErr.Input = inputPath
+			encErr.Output = outputPath
 		}
+		return err
 	}
 
-	// 출력 파일 확인
-	if _, err := os.Stat(outputPath); os.IsNotExist(err) {
-		return fmt.Errorf("encoded file not found: %s", outputPath)
-	}
+	return finalizeOutput(tempPath, outputPath, options.OnConflict == ConflictOverwrite)
+}
 
-	// 완료 상태 업데이트
-	progressCallback(EncodingProgress{
-		Filename: filename,
-		Status:   "completed",
-	})
+// reserveOutput applies the conflict policy to outputPath, also treating the
+// outputs of files still being encoded as taken, and holds the resolved path
+// until release is called so parallel workers never write to the same file
+func (e *Encoder) reserveOutput(options EncodingOptions, outputPath string) (string, func(), error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-	return nil
+	resolved, err := options.resolveConflict(outputPath, e.outputs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := pathKey(resolved)
+	e.outputs[key] = true
+	release := func() {
+		e.mu.Lock()
+		delete(e.outputs, key)
+		e.mu.Unlock()
+	}
+	return resolved, release, nil
 }
 
 // runSinglePassEncoding performs single pass encoding
-func (e *Encoder) runSinglePassEncoding(inputPath, outputPath string, options EncodingOptions, progressCallback func(EncodingProgress)) error {
+func (e *Encoder) runSinglePassEncoding(ctx context.Context, inputPath, outputPath string, options EncodingOptions, progressCallback func(EncodingProgress)) error {
 	args, err := options.BuildFFmpegArgs(inputPath)
 	if err != nil {
 		return fmt.Errorf("failed to build FFmpeg arguments: %w", err)
 	}
 	args = append(args, outputPath)
 
-	return e.runFFmpegCommand(args, filepath.Base(inputPath), progressCallback)
+	return e.runFFmpegCommand(ctx, args, inputPath, singlePass(), progressCallback)
 }
 
 // runTwoPassEncoding performs two pass encoding
-func (e *Encoder) runTwoPassEncoding(inputPath, outputPath string, options EncodingOptions, progressCallback func(EncodingProgress)) error {
-	passLogFile := filepath.Join(os.TempDir(), fmt.Sprintf("ffmpeg2pass_%d", time.Now().UnixNano()))
+func (e *Encoder) runTwoPassEncoding(ctx context.Context, inputPath, outputPath string, options EncodingOptions, progressCallback func(EncodingProgress)) error {
+	passLogFile := filepath.Join(os.TempDir(), fmt.Sprintf("ffmpeg2pass_%d_%d", time.Now().UnixNano(), passLogSeq.Add(1)))
 	pass1Args, pass2Args := options.Build2PassArgs(inputPath, passLogFile)
 
+	// Cleanup temporary files, including after a failed or cancelled pass
+	defer func() {
+		os.Remove(passLogFile + "-0.log")
+		os.Remove(passLogFile + "-0.log.mbtree")
+	}()
+
+	// 두 패스가 하나의 진행률과 경과 시간을 공유
+	clock := newProgressClock()
+
 	// First pass
-	if err := e.runFFmpegCommand(pass1Args, filepath.Base(inputPath), progressCallback); err != nil {
+	pass1 := passInfo{number: 1, total: 2, clock: clock}
+	if err := e.runFFmpegCommand(ctx, pass1Args, inputPath, pass1, progressCallback); err != nil {
 		return fmt.Errorf("first pass failed: %w", err)
 	}
 
 	// Second pass
+	pass2 := passInfo{number: 2, total: 2, clock: clock}
 	pass2Args = append(pass2Args, outputPath)
-	if err := e.runFFmpegCommand(pass2Args, filepath.Base(inputPath), progressCallback); err != nil {
+	if err := e.runFFmpegCommand(ctx, pass2Args, inputPath, pass2, progressCallback); err != nil {
 		return fmt.Errorf("second pass failed: %w", err)
 	}
 
-	// Cleanup temporary files
-	os.Remove(passLogFile + "-0.log")
-	os.Remove(passLogFile + "-0.log.mbtree")
-
 	return nil
 }
 
 // runFFmpegCommand executes the FFmpeg command with progress monitoring
-func (e *Encoder) runFFmpegCommand(args []string, filename string, progressCallback func(EncodingProgress)) error {
-	cmd := exec.Command("ffmpeg", args...)
+func (e *Encoder) runFFmpegCommand(ctx context.Context, args []string, inputPath string, pass passInfo, progressCallback func(EncodingProgress)) error {
+	cmd, err := ffmpeg.Command(ctx, append(append([]string{}, progressArgs...), args...)...)
+	if err != nil {
+		return err
+	}
 
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
-	progressReader := NewProgressReader(progressCallback, filename)
-	go progressReader.ReadProgress(stderr)
+	progressReader := NewProgressReader(progressCallback, filepath.Base(inputPath))
+	progressReader.pass = pass
+
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		progressReader.ReadProgress(stdout)
+	}()
+	go func() {
+		defer readers.Done()
+		progressReader.ReadDiagnostics(stderr)
+	}()
 
 	if err := cmd.Start(); err != nil {
+		// 패스 사이나 실행 직전에 취소된 경우
+		if ctx.Err() != nil {
+			return ErrCancelled
+		}
 		return fmt.Errorf("failed to start encoding: %w", err)
 	}
 
+	e.attachProcess(inputPath, cmd, progressReader)
+	defer e.attachProcess(inputPath, nil, nil)
+
+	// stdout과 stderr를 모두 읽은 뒤에 Wait 호출
+	readers.Wait()
 	if err := cmd.Wait(); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return fmt.Errorf("encoding failed: %w\nError output:\n%s", err, string(exitErr.Stderr))
+		if ctx.Err() != nil {
+			return ErrCancelled
+		}
+		exitCode := -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		return &EncodeError{
+			Pass:       pass.number,
+			Args:       cmd.Args[1:],
+			ExitCode:   exitCode,
+			StderrTail: progressReader.StderrTail(),
+			err:        err,
 		}
-		return fmt.Errorf("encoding failed: %w", err)
 	}
 
 	return nil
 }
+
+// attachProcess records the running process for a file so it can be paused.
+// A file paused between passes is suspended as soon as its next pass starts.
+func (e *Encoder) attachProcess(inputPath string, cmd *exec.Cmd, reader *ProgressReader) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	job, ok := e.jobs[inputPath]
+	if !ok {
+		return
+	}
+	job.cmd = cmd
+	job.reader = reader
+
+	if cmd != nil && job.paused {
+		if err := suspendProcess(cmd.Process); err != nil {
+			job.paused = false
+			return
+		}
+		reader.SetPaused(true)
+	}
+}
+
+// EncodeError describes a failed ffmpeg run in enough detail for the
+// frontend to show it in a details panel
+type EncodeError struct {
+	Input      string   `json:"input"`
+	Output     string   `json:"output"`
+	Pass       int      `json:"pass"` // 1 또는 2 (1-pass 인코딩은 1)
+	Args       []string `json:"args"`
+	ExitCode   int      `json:"exitcode"` // 종료 코드를 알 수 없으면 -1
+	StderrTail string   `json:"stderrtail"`
+
+	err error
+}
+
+func (ee *EncodeError) Error() string {
+	if ee.StderrTail == "" {
+		return fmt.Sprintf("encoding failed: %v", ee.err)
+	}
+	return fmt.Sprintf("encoding failed: %v\nError output:\n%s", ee.err, ee.StderrTail)
+}
+
+func (ee *EncodeError) Unwrap() error {
+	return ee.err
+}
@@ -0,0 +1,252 @@
+// pkg/encoder/encoders.go
+package encoder
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"encoder/pkg/codec"
+)
+
+// qualityRange is the accepted constant quality values of an encoder
+type qualityRange struct {
+	min, max int
+	default_ int
+}
+
+// encoderSpec maps the abstract QualityMode and QualityValue to the rate
+// control flags of one encoder
+type encoderSpec struct {
+	defaultMode QualityMode
+	quality     qualityRange
+	qualityArgs func(value int) []string // CRF 모드 인자
+	twoPass     bool                     // -pass 1/2 지원 여부
+
+	presetFlag    string   // Preset을 전달할 옵션 (기본값: -preset)
+	defaultPreset string   // Preset을 지정하지 않았을 때 사용 (ffmpeg 기본값이 지나치게 느린 인코더)
+	extraArgs     []string // 항상 추가하는 인코더 옵션
+}
+
+func crfArgs(flag string, extra ...string) func(int) []string {
+	return func(v int) []string {
+		return append([]string{flag, strconv.Itoa(v)}, extra...)
+	}
+}
+
+// 인코더 프로파일 표 (키: EncodingOptions.VideoCodec)
+// 중간 코덱(prores, dnxhr, ffv1)과 copy는 화질 설정을 사용하지 않으므로 없음
+var encoderSpecs = map[string]encoderSpec{
+	"h264": { // libx264
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 0, max: 51, default_: 23},
+		qualityArgs: crfArgs("-crf"),
+		twoPass:     true,
+	},
+	"hevc": { // libx265
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 0, max: 51, default_: 28},
+		qualityArgs: crfArgs("-crf"),
+		twoPass:     true,
+	},
+	"h264_nvenc": {
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 0, max: 51, default_: 23},
+		// -b:v 0이 없으면 기본 비트레이트 제한이 -cq보다 우선함
+		qualityArgs: crfArgs("-cq", "-rc", "vbr", "-b:v", "0"),
+	},
+	"hevc_nvenc": {
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 0, max: 51, default_: 28},
+		qualityArgs: crfArgs("-cq", "-rc", "vbr", "-b:v", "0"),
+	},
+	"h264_qsv": {
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 1, max: 51, default_: 23},
+		qualityArgs: crfArgs("-global_quality"),
+	},
+	"hevc_qsv": {
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 1, max: 51, default_: 28},
+		qualityArgs: crfArgs("-global_quality"),
+	},
+	"h264_videotoolbox": {
+		// -q:v는 값이 클수록 화질이 높음
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 1, max: 100, default_: 65},
+		qualityArgs: crfArgs("-q:v"),
+	},
+	"hevc_videotoolbox": {
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 1, max: 100, default_: 65},
+		qualityArgs: crfArgs("-q:v"),
+	},
+	"vp8": { // libvpx
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 4, max: 63, default_: 10},
+		// VP8의 CRF는 최대 비트레이트가 필요한 제한 화질 모드이므로 넉넉한 상한을 지정
+		qualityArgs: crfArgs("-crf", "-b:v", "50M"),
+		twoPass:     true,
+	},
+	"vp9": { // libvpx-vp9
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 0, max: 63, default_: 31},
+		// -b:v 0이어야 제한 없는 고정 화질 모드
+		qualityArgs: crfArgs("-crf", "-b:v", "0"),
+		twoPass:     true,
+	},
+	"av1": { // libsvtav1
+		defaultMode:   QualityModeCRF,
+		quality:       qualityRange{min: 0, max: 63, default_: 35},
+		qualityArgs:   crfArgs("-crf"),
+		defaultPreset: "8",
+	},
+	"libaom-av1": {
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 0, max: 63, default_: 30},
+		// -b:v 0이 없으면 CRF가 최대 비트레이트 제한 모드로 동작함
+		qualityArgs: crfArgs("-crf", "-b:v", "0"),
+		twoPass:     true,
+
+		presetFlag:    "-cpu-used",
+		defaultPreset: "6",
+		extraArgs:     []string{"-row-mt", "1"},
+	},
+	"librav1e": {
+		defaultMode: QualityModeCRF,
+		quality:     qualityRange{min: 0, max: 255, default_: 100},
+		qualityArgs: crfArgs("-qp"),
+
+		presetFlag:    "-speed",
+		defaultPreset: "6",
+	},
+	"av1_nvenc": {
+		defaultMode:   QualityModeCRF,
+		quality:       qualityRange{min: 0, max: 51, default_: 30},
+		qualityArgs:   crfArgs("-cq", "-rc", "vbr", "-b:v", "0"),
+		defaultPreset: "p5",
+	},
+	"av1_qsv": {
+		defaultMode:   QualityModeCRF,
+		quality:       qualityRange{min: 1, max: 51, default_: 30},
+		qualityArgs:   crfArgs("-global_quality"),
+		defaultPreset: "medium",
+	},
+}
+
+// encoderSpec returns the rate control settings of the selected video codec
+func (opts *EncodingOptions) encoderSpec() (encoderSpec, bool) {
+	spec, ok := encoderSpecs[opts.VideoCodec]
+	return spec, ok
+}
+
+// validateQuality fills in the encoder's default quality when none was chosen
+// and checks the value against the encoder's range
+func (spec encoderSpec) validateQuality(opts *EncodingOptions) error {
+	if opts.QualityValue == 0 {
+		opts.QualityMode = spec.defaultMode
+		opts.QualityValue = spec.quality.default_
+	}
+	if opts.QualityMode == "" {
+		opts.QualityMode = spec.defaultMode
+	}
+
+	switch opts.QualityMode {
+	case QualityModeCRF:
+		if opts.QualityValue < spec.quality.min || opts.QualityValue > spec.quality.max {
+			return fmt.Errorf("quality value %d out of range [%d-%d] for codec %s",
+				opts.QualityValue,
+				spec.quality.min,
+				spec.quality.max,
+				opts.VideoCodec)
+		}
+	case QualityModeBitrate:
+		if opts.QualityValue <= 0 {
+			return fmt.Errorf("bitrate must be positive: %d", opts.QualityValue)
+		}
+	default:
+		return fmt.Errorf("unsupported quality mode: %s", opts.QualityMode)
+	}
+
+	if opts.Use2Pass {
+		if !spec.twoPass {
+			return fmt.Errorf("2-pass encoding is not available for codec %s", opts.VideoCodec)
+		}
+		if opts.QualityMode != QualityModeBitrate {
+			return fmt.Errorf("2-pass encoding is only available with bitrate mode")
+		}
+	}
+	return nil
+}
+
+// rateControlArgs returns the quality or bitrate arguments
+func (spec encoderSpec) rateControlArgs(mode QualityMode, value int) []string {
+	switch mode {
+	case QualityModeCRF:
+		return spec.qualityArgs(value)
+	case QualityModeBitrate:
+		return []string{"-b:v", fmt.Sprintf("%dk", value)}
+	}
+	return nil
+}
+
+// validateTuning checks Preset, Tune, Profile and Level against the values the
+// codec accepts
+func (opts *EncodingOptions) validateTuning() error {
+	allowed := codec.OptionsFor(opts.VideoCodec)
+
+	for _, option := range []struct {
+		name   string
+		value  string
+		values []string
+	}{
+		{"preset", opts.Preset, allowed.Presets},
+		{"tune", opts.Tune, allowed.Tunes},
+		{"profile", opts.Profile, allowed.Profiles},
+		{"level", opts.Level, allowed.Levels},
+	} {
+		if option.value == "" {
+			continue
+		}
+		if len(option.values) == 0 {
+			return fmt.Errorf("codec %s does not take a %s", opts.VideoCodec, option.name)
+		}
+		if !slices.Contains(option.values, option.value) {
+			return fmt.Errorf("unsupported %s %s for codec %s (supported: %s)",
+				option.name, option.value, opts.VideoCodec, strings.Join(option.values, ", "))
+		}
+	}
+	return nil
+}
+
+// tuningArgs returns the preset, tune, profile and level arguments, falling
+// back to the encoder's default preset
+func (opts *EncodingOptions) tuningArgs() []string {
+	spec, _ := opts.encoderSpec()
+
+	var args []string
+	preset := opts.Preset
+	if preset == "" {
+		preset = spec.defaultPreset
+	}
+	if preset != "" {
+		flag := spec.presetFlag
+		if flag == "" {
+			flag = "-preset"
+		}
+		args = append(args, flag, preset)
+	}
+	args = append(args, spec.extraArgs...)
+
+	if opts.Tune != "" {
+		args = append(args, "-tune", opts.Tune)
+	}
+	if opts.Profile != "" {
+		args = append(args, "-profile:v", opts.Profile)
+	}
+	if opts.Level != "" {
+		args = append(args, "-level", opts.Level)
+	}
+	return args
+}
@@ -0,0 +1,72 @@
+// pkg/encoder/intermediate.go
+package encoder
+
+import "fmt"
+
+// intermediateCodec describes a mezzanine codec used for editing round-trips.
+// Its quality is set by the profile rather than by CRF or bitrate.
+type intermediateCodec struct {
+	defaultProfile string            // 프로파일을 지정하지 않았을 때 사용 (비어 있으면 프로파일 없음)
+	pixFmts        map[string]string // 프로파일별 픽셀 포맷
+	extraArgs      []string
+	audioCodec     string // 오디오 코덱을 지정하지 않았을 때 사용
+}
+
+var intermediateCodecs = map[string]intermediateCodec{
+	"prores": {
+		defaultProfile: "hq",
+		pixFmts: map[string]string{
+			"proxy":    "yuv422p10le",
+			"lt":       "yuv422p10le",
+			"standard": "yuv422p10le",
+			"hq":       "yuv422p10le",
+			"4444":     "yuva444p10le",
+		},
+		// 일부 NLE는 Apple 벤더 ID가 없으면 파일을 거부함
+		extraArgs:  []string{"-vendor", "apl0"},
+		audioCodec: "pcm_s16le",
+	},
+	"dnxhr": {
+		defaultProfile: "dnxhr_hq",
+		pixFmts: map[string]string{
+			"dnxhr_lb":  "yuv422p",
+			"dnxhr_sq":  "yuv422p",
+			"dnxhr_hq":  "yuv422p",
+			"dnxhr_hqx": "yuv422p10le",
+			"dnxhr_444": "yuv444p10le",
+		},
+		audioCodec: "pcm_s16le",
+	},
+	"ffv1": {
+		// 보관용 설정: 버전 3, 모든 프레임이 키프레임, 슬라이스 CRC
+		extraArgs:  []string{"-level", "3", "-g", "1", "-slices", "16", "-slicecrc", "1"},
+		audioCodec: "pcm_s16le",
+	},
+}
+
+// intermediate returns the mezzanine codec settings for the selected video codec
+func (opts *EncodingOptions) intermediate() (intermediateCodec, bool) {
+	ic, ok := intermediateCodecs[opts.VideoCodec]
+	return ic, ok
+}
+
+// applyDefaults rejects 2-pass encoding and fills in the default profile when
+// none was chosen. The profile itself is checked with the other tuning options.
+func (ic intermediateCodec) applyDefaults(opts *EncodingOptions) error {
+	if opts.Use2Pass {
+		return fmt.Errorf("2-pass encoding is not available for codec %s", opts.VideoCodec)
+	}
+	if opts.Profile == "" {
+		opts.Profile = ic.defaultProfile
+	}
+	return nil
+}
+
+// videoArgs returns the pixel format and codec specific arguments for the profile
+func (ic intermediateCodec) videoArgs(profile string) []string {
+	var args []string
+	if pixFmt := ic.pixFmts[profile]; pixFmt != "" {
+		args = append(args, "-pix_fmt", pixFmt)
+	}
+	return append(args, ic.extraArgs...)
+}
@@ -2,12 +2,17 @@
 package encoder
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
 	"encoder/pkg/codec"
+	"encoder/pkg/fileutil"
+	"encoder/pkg/video"
 )
 
 type QualityMode string
@@ -17,6 +22,24 @@ const (
 	QualityModeBitrate QualityMode = "bitrate"
 )
 
+// BatchPolicy decides what happens to the rest of a batch when a file fails
+type BatchPolicy string
+
+const (
+	BatchStopOnError     BatchPolicy = "stop"
+	BatchContinueOnError BatchPolicy = "continue"
+)
+
+// ConflictPolicy decides what happens when an output file already exists
+type ConflictPolicy string
+
+const (
+	ConflictError     ConflictPolicy = "error"
+	ConflictSkip      ConflictPolicy = "skip"
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	ConflictRename    ConflictPolicy = "rename" // name (1).mp4, name (2).mp4, ...
+)
+
 type EncodingOptions struct {
 	VideoFormat  string      `json:"videoformat"`
 	VideoCodec   string      `json:"videocodec"`
@@ -24,62 +47,53 @@ type EncodingOptions struct {
 	QualityValue int         `json:"qualityvalue"`
 	Use2Pass     bool        `json:"use2pass"`
 
+	// 인코더 튜닝 옵션 (허용 값은 코덱마다 다름, GetAvailableCodecs 참고)
+	Preset  string `json:"preset"`  // -preset (libaom-av1: -cpu-used, librav1e: -speed)
+	Tune    string `json:"tune"`    // -tune
+	Profile string `json:"profile"` // -profile:v (ProRes: proxy/lt/standard/hq/4444, DNxHR: dnxhr_lb/sq/hq/hqx/444)
+	Level   string `json:"level"`   // -level
+
 	// 크기 조정 옵션
 	IsResize bool `json:"isresize"`
 	Width    int  `json:"width"`
 	Height   int  `json:"height"`
 
-	// 출력 경로 옵션
-	OutputPath string `json:"outputpath"`
-	Prefix     string `json:"prefix"`
-	Postfix    string `json:"postfix"`
+	// 출력 경로 옵션 (여러 파일을 인코딩할 때 OutputPath는 출력 디렉토리)
+	OutputPath string         `json:"outputpath"`
+	Prefix     string         `json:"prefix"`
+	Postfix    string         `json:"postfix"`
+	OnConflict ConflictPolicy `json:"onconflict"` // 기본값: error
+
+	// 출력 루트 모드: 드롭한 폴더 구조를 OutputPath 아래에 그대로 재현
+	MirrorTree  bool     `json:"mirrortree"`
+	SourceRoots []string `json:"sourceroots"` // 드롭한 파일/폴더 경로 (비어 있으면 입력 파일의 공통 상위 디렉토리를 드롭한 폴더로 봄)
+
+	// 출력 파일 이름 템플릿 (예: "{name}_{codec}_{width}x{height}"), 설정 시 Prefix/Postfix 무시
+	OutputTemplate string `json:"outputtemplate"`
 
 	// 오디오 옵션
 	AudioCodec      string `json:"audiocodec"`
 	AudioBitrate    int    `json:"audiobitrate"`
 	AudioSamplerate int    `json:"audiosamplerate"`
+
+	// 병렬 처리 옵션 (0이면 코덱별 기본값 사용)
+	MaxConcurrency int `json:"maxconcurrency"`
+
+	// 오류 처리 옵션 (기본값: stop)
+	OnError BatchPolicy `json:"onerror"`
 }
 
-// 코덱별 설정 정의
-var codecSettings = map[string]struct {
-	defaultMode  QualityMode
-	qualityRange struct {
-		min, max int
-		default_ int
-	}
-}{
-	"h264": {
-		defaultMode: QualityModeCRF,
-		qualityRange: struct{ min, max, default_ int }{
-			min:      0,
-			max:      51,
-			default_: 23,
-		},
-	},
-	"hevc": {
-		defaultMode: QualityModeCRF,
-		qualityRange: struct{ min, max, default_ int }{
-			min:      0,
-			max:      51,
-			default_: 28,
-		},
-	},
-	"vp9": {
-		defaultMode: QualityModeCRF,
-		qualityRange: struct{ min, max, default_ int }{
-			min:      0,
-			max:      63,
-			default_: 31,
-		},
-	},
-}
-
-func (opts *EncodingOptions) getOutputPath(inputPath string) string {
-	if opts.OutputPath != "" {
-		return opts.OutputPath
-	}
-
-	dir := filepath.Dir(inputPath)
+// outputName returns the output file name for inputPath, either resolved from
+// OutputTemplate or built as prefix + name + postfix, plus the format extension
+func (opts *EncodingOptions) outputName(inputPath string, tc templateContext) (string, error) {
+	if opts.OutputTemplate != "" {
+		name, err := opts.expandTemplate(inputPath, tc)
+		if err != nil {
+			return "", err
+		}
+		return name + "." + opts.extension(), nil
+	}
+
 	filename := filepath.Base(inputPath)
 	ext := filepath.Ext(filename)
 	nameWithoutExt := strings.TrimSuffix(filename, ext)
@@ -92,49 +106,205 @@ func (opts *EncodingOptions) getOutputPath(inputPath string) string {
 		newName = newName + opts.Postfix
 	}
 
-	return filepath.Join(dir, newName+"."+opts.VideoFormat)
+	return newName + "." + opts.extension(), nil
 }
 
-func (opts *EncodingOptions) Validate() error {
-	// Format validation
-	supportedCodecs, exists := codec.SupportedFormats[opts.VideoFormat]
-	if !exists {
-		return fmt.Errorf("unsupported video format: %s", opts.VideoFormat)
-	}
-
-	// Codec validation
-	codecSupported := false
-	for _, codec := range supportedCodecs {
-		if opts.VideoCodec == codec {
-			codecSupported = true
-			break
+// extension returns the output file extension of the container format
+func (opts *EncodingOptions) extension() string {
+	if c, ok := codec.LookupContainer(opts.VideoFormat); ok {
+		return c.Extension
+	}
+	return opts.VideoFormat
+}
+
+// resizes reports whether the output is scaled, which needs both dimensions
+func (opts *EncodingOptions) resizes() bool {
+	return opts.IsResize && opts.Width > 0 && opts.Height > 0
+}
+
+// remux reports whether the video stream is copied instead of re-encoded
+func (opts *EncodingOptions) remux() bool {
+	return opts.VideoCodec == codec.CopyCodec
+}
+
+// outputDirMode reports whether OutputPath names a directory rather than a file.
+// It does when the batch has several inputs or the path is an existing directory.
+func (opts *EncodingOptions) outputDirMode(inputCount int) bool {
+	if opts.OutputPath == "" {
+		return false
+	}
+	if inputCount > 1 || opts.MirrorTree || strings.HasSuffix(opts.OutputPath, string(filepath.Separator)) {
+		return true
+	}
+	info, err := os.Stat(opts.OutputPath)
+	return err == nil && info.IsDir()
+}
+
+// OutputPaths maps every input of a batch to its output path, in input order.
+// Without OutputPath each output is written next to its source. It fails when
+// two inputs would be written to the same file or an output would replace its input.
+func (opts *EncodingOptions) OutputPaths(inputs []string) ([]string, error) {
+	dirMode := opts.outputDirMode(len(inputs))
+	date := time.Now().Format("2006-01-02")
+
+	var mirror *treeMirror
+	if dirMode && opts.MirrorTree {
+		mirror = newTreeMirror(opts.SourceRoots, inputs)
+	}
+
+	outputs := make([]string, len(inputs))
+	seen := make(map[string]string, len(inputs))
+	for i, input := range inputs {
+		tc := templateContext{index: i, count: len(inputs), date: date}
+		if opts.templateNeedsProbe() {
+			metadata, err := video.ProcessVideo(input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read video size for output template (%s): %w", input, err)
+			}
+			tc.metadata = metadata
+		}
+
+		name, err := opts.outputName(input, tc)
+		if err != nil {
+			return nil, err
+		}
+
+		var output string
+		switch {
+		case mirror != nil:
+			relDir, err := mirror.relativeDir(input)
+			if err != nil {
+				return nil, err
+			}
+			output = filepath.Join(opts.OutputPath, relDir, name)
+		case dirMode:
+			output = filepath.Join(opts.OutputPath, name)
+		case opts.OutputPath != "":
+			output = opts.OutputPath
+		default:
+			output = filepath.Join(filepath.Dir(input), name)
+		}
+
+		key := pathKey(output)
+		if key == pathKey(input) {
+			return nil, fmt.Errorf("output would overwrite its input: %s", input)
+		}
+		if other, exists := seen[key]; exists {
+			return nil, fmt.Errorf("%s and %s would both be written to %s", other, input, output)
+		}
+		seen[key] = input
+		outputs[i] = output
+	}
+
+	return outputs, nil
+}
+
+// pathKey returns the form of path used to compare output paths. Case is
+// only folded on Windows and macOS, whose file systems ignore it by default.
+func pathKey(path string) string {
+	path = filepath.Clean(path)
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// resolveConflict applies OnConflict to an output path that may already exist
+// or be reserved by a file still being encoded (keyed by pathKey). It returns
+// the path to write to, or ErrOutputExists if the file should not be written.
+func (opts *EncodingOptions) resolveConflict(outputPath string, reserved map[string]bool) (string, error) {
+	taken := func(path string) bool {
+		if reserved[pathKey(path)] {
+			return true
+		}
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	if !taken(outputPath) {
+		return outputPath, nil
+	}
+
+	switch opts.OnConflict {
+	case ConflictOverwrite:
+		// 다른 파일이 쓰고 있는 경로는 덮어쓰지 않음
+		if !reserved[pathKey(outputPath)] {
+			return outputPath, nil
 		}
+	case ConflictRename:
+		return fileutil.NextFreePath(outputPath, taken), nil
 	}
-	if !codecSupported {
-		return fmt.Errorf("unsupported codec %s for format %s", opts.VideoCodec, opts.VideoFormat)
+	return "", fmt.Errorf("%w: %s", ErrOutputExists, outputPath)
+}
+
+// continueOnError reports whether the batch keeps going after a failed file
+func (opts *EncodingOptions) continueOnError() bool {
+	return opts.OnError == BatchContinueOnError
+}
+
+// skips reports whether err means the file was skipped rather than failed
+func (opts *EncodingOptions) skips(err error) bool {
+	if !errors.Is(err, ErrOutputExists) {
+		return false
 	}
+	return opts.OnConflict == ConflictSkip || opts.continueOnError()
+}
 
-	// Quality settings validation
-	baseCodec := strings.Split(opts.VideoCodec, "_")[0]
-	codecSet, exists := codecSettings[baseCodec]
-	if exists {
-		if opts.QualityValue == 0 {
-			opts.QualityMode = codecSet.defaultMode
-			opts.QualityValue = codecSet.qualityRange.default_
+func (opts *EncodingOptions) Validate() error {
+	// 컨테이너/코덱 호환성 검사
+	if err := codec.CheckCompatibility(opts.VideoFormat, opts.VideoCodec, opts.AudioCodec); err != nil {
+		return err
+	}
+
+	// remux는 스트림을 그대로 복사하므로 인코딩 관련 옵션을 사용할 수 없음
+	if opts.remux() {
+		switch {
+		case opts.IsResize:
+			return fmt.Errorf("resizing requires re-encoding and cannot be used with video codec copy")
+		case opts.Use2Pass:
+			return fmt.Errorf("2-pass encoding cannot be used with video codec copy")
 		}
+	}
 
-		if opts.QualityValue < codecSet.qualityRange.min ||
-			opts.QualityValue > codecSet.qualityRange.max {
-			return fmt.Errorf("quality value %d out of range [%d-%d] for codec %s",
-				opts.QualityValue,
-				codecSet.qualityRange.min,
-				codecSet.qualityRange.max,
-				opts.VideoCodec)
+	// 중간 코덱은 CRF/비트레이트 대신 프로파일로 화질을 결정
+	if ic, ok := opts.intermediate(); ok {
+		if err := ic.applyDefaults(opts); err != nil {
+			return err
 		}
 	}
 
-	if opts.Use2Pass && opts.QualityMode != QualityModeBitrate {
-		return fmt.Errorf("2-pass encoding is only available with bitrate mode")
+	if err := opts.validateTuning(); err != nil {
+		return err
+	}
+
+	// Quality settings validation (인코더별 값 범위)
+	if spec, ok := opts.encoderSpec(); ok {
+		if err := spec.validateQuality(opts); err != nil {
+			return err
+		}
+	}
+
+	switch opts.OnError {
+	case "", BatchStopOnError, BatchContinueOnError:
+	default:
+		return fmt.Errorf("unsupported batch policy: %s", opts.OnError)
+	}
+
+	if opts.MirrorTree && opts.OutputPath == "" {
+		return fmt.Errorf("mirroring the source tree requires an output directory")
+	}
+
+	if err := opts.validateTemplate(); err != nil {
+		return err
+	}
+
+	switch opts.OnConflict {
+	case "", ConflictError, ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return fmt.Errorf("unsupported conflict policy: %s", opts.OnConflict)
+	}
+
+	if opts.MaxConcurrency < 0 {
+		return fmt.Errorf("max concurrency must not be negative: %d", opts.MaxConcurrency)
 	}
 
 	return nil
@@ -143,78 +313,126 @@ func (opts *EncodingOptions) Validate() error {
 func (opts *EncodingOptions) BuildFFmpegArgs(inputPath string) ([]string, error) {
 	args := []string{"-i", inputPath}
 
+	// Stream selection
+	args = append(args, opts.streamArgs(inputPath)...)
+
 	// Video codec
-	args = append(args, "-c:v", opts.VideoCodec)
+	args = append(args, "-c:v", codec.VideoEncoder(opts.VideoCodec))
 
 	// Quality settings
-	switch opts.QualityMode {
-	case QualityModeCRF:
-		args = append(args, "-crf", fmt.Sprintf("%d", opts.QualityValue))
-	case QualityModeBitrate:
-		args = append(args, "-b:v", fmt.Sprintf("%dk", opts.QualityValue))
+	if ic, ok := opts.intermediate(); ok {
+		args = append(args, ic.videoArgs(opts.Profile)...)
+	} else if spec, ok := opts.encoderSpec(); ok {
+		args = append(args, spec.rateControlArgs(opts.QualityMode, opts.QualityValue)...)
 	}
+	args = append(args, opts.tuningArgs()...)
 
 	// Resize settings
-	if opts.IsResize && opts.Width > 0 && opts.Height > 0 {
+	if opts.resizes() {
 		args = append(args, "-vf", fmt.Sprintf("scale=%d:%d", opts.Width, opts.Height))
 	}
 
 	// Audio settings
-	if opts.AudioCodec != "" {
-		args = append(args, "-c:a", opts.AudioCodec)
-	} else {
-		args = append(args, "-c:a", "copy")
+	args = append(args, opts.audioArgs()...)
+
+	return args, nil
+}
+
+// streamArgs maps every video, audio and subtitle stream of the input instead
+// of ffmpeg's default of one stream per type. Each subtitle stream is copied,
+// converted to the container's text format or dropped, as the container
+// table allows; if the input cannot be probed subtitles are dropped.
+func (opts *EncodingOptions) streamArgs(inputPath string) []string {
+	// 다시 인코딩할 때는 커버 이미지(attached pic)를 비디오 스트림으로 취급하지 않음
+	videoMap := "0:V?"
+	if opts.remux() {
+		videoMap = "0:v?"
+	}
+	args := []string{"-map", videoMap, "-map", "0:a?"}
+
+	c, ok := codec.LookupContainer(opts.VideoFormat)
+	if !ok {
+		return args
+	}
+	if c.Attachments {
+		args = append(args, "-map", "0:t?", "-c:t", "copy")
+	}
+
+	metadata, err := video.ProcessVideo(inputPath)
+	if err != nil {
+		return args
+	}
+	out := 0
+	for _, stream := range metadata.SubtitleStreams {
+		encoder := c.SubtitleEncoder(stream.Codec)
+		if encoder == "" {
+			continue
+		}
+		args = append(args,
+			"-map", fmt.Sprintf("0:%d", stream.Index),
+			fmt.Sprintf("-c:s:%d", out), encoder,
+		)
+		out++
+	}
+	return args
+}
+
+// audioArgs returns the audio codec, bitrate and sample rate arguments. Without
+// an audio codec the audio is copied, except for intermediate codecs which
+// default to PCM as editors expect.
+func (opts *EncodingOptions) audioArgs() []string {
+	audioCodec := opts.AudioCodec
+	if audioCodec == "" {
+		audioCodec = "copy"
+		if ic, ok := opts.intermediate(); ok {
+			audioCodec = ic.audioCodec
+		}
 	}
+
+	args := []string{"-c:a", audioCodec}
 	if opts.AudioBitrate > 0 {
 		args = append(args, "-b:a", fmt.Sprintf("%dk", opts.AudioBitrate))
 	}
 	if opts.AudioSamplerate > 0 {
 		args = append(args, "-ar", fmt.Sprintf("%d", opts.AudioSamplerate))
 	}
-
-	return args, nil
+	return args
 }
 
 func (opts *EncodingOptions) Build2PassArgs(inputPath string, passLogFile string) ([]string, []string) {
 	// First pass arguments
 	pass1Args := []string{
 		"-i", inputPath,
-		"-c:v", opts.VideoCodec,
+		"-map", "0:V?",
+		"-c:v", codec.VideoEncoder(opts.VideoCodec),
 		"-b:v", fmt.Sprintf("%dk", opts.QualityValue),
 		"-pass", "1",
 		"-passlogfile", passLogFile,
 		"-an",
 		"-f", "null",
 	}
-	if opts.IsResize && opts.Width > 0 && opts.Height > 0 {
+	pass1Args = append(pass1Args, opts.tuningArgs()...)
+	if opts.resizes() {
 		pass1Args = append(pass1Args, "-vf", fmt.Sprintf("scale=%d:%d", opts.Width, opts.Height))
 	}
 	pass1Args = append(pass1Args, os.DevNull)
 
 	// Second pass arguments
-	pass2Args := []string{
-		"-i", inputPath,
-		"-c:v", opts.VideoCodec,
+	pass2Args := []string{"-i", inputPath}
+	pass2Args = append(pass2Args, opts.streamArgs(inputPath)...)
+	pass2Args = append(pass2Args,
+		"-c:v", codec.VideoEncoder(opts.VideoCodec),
 		"-b:v", fmt.Sprintf("%dk", opts.QualityValue),
 		"-pass", "2",
 		"-passlogfile", passLogFile,
-	}
-	if opts.IsResize && opts.Width > 0 && opts.Height > 0 {
+	)
+	pass2Args = append(pass2Args, opts.tuningArgs()...)
+	if opts.resizes() {
 		pass2Args = append(pass2Args, "-vf", fmt.Sprintf("scale=%d:%d", opts.Width, opts.Height))
 	}
 
 	// Audio settings for second pass
-	if opts.AudioCodec != "" {
-		pass2Args = append(pass2Args, "-c:a", opts.AudioCodec)
-	} else {
-		pass2Args = append(pass2Args, "-c:a", "copy")
-	}
-	if opts.AudioBitrate > 0 {
-		pass2Args = append(pass2Args, "-b:a", fmt.Sprintf("%dk", opts.AudioBitrate))
-	}
-	if opts.AudioSamplerate > 0 {
-		pass2Args = append(pass2Args, "-ar", fmt.Sprintf("%d", opts.AudioSamplerate))
-	}
+	pass2Args = append(pass2Args, opts.audioArgs()...)
 
 	return pass1Args, pass2Args
 }
@@ -0,0 +1,188 @@
+// pkg/encoder/output.go
+package encoder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"encoder/pkg/video"
+)
+
+// partialMarker is part of the name of every file ffmpeg is still writing
+const partialMarker = ".partial"
+
+// OutputPreview shows where an input will be written before encoding starts
+type OutputPreview struct {
+	InputPath  string `json:"inputpath"`
+	OutputPath string `json:"outputpath"`
+	Exists     bool   `json:"exists"` // 출력 경로에 이미 파일이 있는지 여부
+}
+
+// PreviewOutputs resolves the output path of every input with the same rules
+// as an actual batch, including the conflict policy
+func PreviewOutputs(paths []string, options EncodingOptions) ([]OutputPreview, error) {
+	if err := options.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid encoding options: %w", err)
+	}
+
+	outputs, err := options.OutputPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	previews := make([]OutputPreview, len(paths))
+	for i, path := range paths {
+		preview := OutputPreview{InputPath: path, OutputPath: outputs[i]}
+		if _, err := os.Stat(outputs[i]); err == nil {
+			preview.Exists = true
+			if resolved, err := options.resolveConflict(outputs[i], nil); err == nil {
+				preview.OutputPath = resolved
+			}
+		}
+		previews[i] = preview
+	}
+
+	return previews, nil
+}
+
+// partialPath returns the temporary path ffmpeg writes to before the result
+// is renamed to outputPath. The extension is kept so ffmpeg picks the same muxer.
+func partialPath(outputPath string) string {
+	dir := filepath.Dir(outputPath)
+	name := filepath.Base(outputPath)
+	ext := filepath.Ext(name)
+	return filepath.Join(dir, "."+strings.TrimSuffix(name, ext)+partialMarker+ext)
+}
+
+// finalizeOutput checks the finished partial file and moves it to outputPath.
+// An existing file at outputPath is only replaced when overwrite is set.
+func finalizeOutput(tempPath, outputPath string, overwrite bool) error {
+	if err := verifyOutput(tempPath); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	// 인코딩 중에 다른 프로세스가 출력 파일을 만든 경우
+	if _, err := os.Stat(outputPath); err == nil && !overwrite {
+		os.Remove(tempPath)
+		return fmt.Errorf("%w: %s", ErrOutputExists, outputPath)
+	}
+
+	if err := os.Rename(tempPath, outputPath); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to move encoded file to %s: %w", outputPath, err)
+	}
+	return nil
+}
+
+// verifyOutput probes an encoded file to make sure ffmpeg produced a readable result
+func verifyOutput(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("encoded file not found: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read encoded file (%s): %w", path, err)
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("encoded file is empty: %s", path)
+	}
+
+	metadata, err := video.ProcessVideo(path)
+	if err != nil {
+		return fmt.Errorf("encoded file failed probe check (%s): %w", path, err)
+	}
+	// 오디오만 있거나 스트림을 복사한 출력도 정상으로 처리
+	if len(metadata.VideoStreams)+len(metadata.AudioStreams)+len(metadata.SubtitleStreams) == 0 {
+		return fmt.Errorf("encoded file has no streams: %s", path)
+	}
+	return nil
+}
+
+// SweepPartials removes partial files left by an encode that never finished,
+// e.g. because the app was closed. Only the given paths are removed, as
+// returned by Queue.PartialPaths, so files in output directories the app did
+// not write are never touched. It must not be called while encoding is in progress.
+func SweepPartials(paths []string) []error {
+	var errs []error
+
+	for _, path := range paths {
+		if !strings.Contains(filepath.Base(path), partialMarker) {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to remove partial file (%s): %w", path, err))
+		}
+	}
+
+	return errs
+}
+
+// treeMirror recreates the folder structure of dropped folders under an output root
+type treeMirror struct {
+	roots []string
+}
+
+func newTreeMirror(roots []string, inputs []string) *treeMirror {
+	m := &treeMirror{}
+	for _, root := range roots {
+		m.roots = append(m.roots, filepath.Clean(root))
+	}
+	// 드롭한 경로가 없으면 입력 파일의 공통 상위 디렉토리를 드롭한 폴더로 간주
+	if len(m.roots) == 0 && len(inputs) > 0 {
+		m.roots = []string{commonDir(inputs)}
+	}
+	return m
+}
+
+// relativeDir returns the directory of input relative to the output root.
+// A dropped folder keeps its own name, so dropping shoots/2026 writes
+// shoots/2026/a/x.mp4 to <output>/2026/a/x.mp4.
+func (m *treeMirror) relativeDir(input string) (string, error) {
+	// 입력 파일을 포함하는 가장 깊은 루트 선택
+	best := ""
+	for _, root := range m.roots {
+		if isWithin(root, input) && len(root) > len(best) {
+			best = root
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("%s is not inside any of the source folders", input)
+	}
+
+	if best == filepath.Clean(input) {
+		// 파일을 직접 드롭한 경우 출력 루트 바로 아래에 생성
+		return ".", nil
+	}
+	return filepath.Rel(filepath.Dir(best), filepath.Dir(input))
+}
+
+// isWithin reports whether path is root or lies below it
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// commonDir returns the deepest directory containing every path
+func commonDir(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+
+	dir := filepath.Dir(filepath.Clean(paths[0]))
+	for _, path := range paths[1:] {
+		for !isWithin(dir, path) {
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	return dir
+}
@@ -0,0 +1,259 @@
+// pkg/encoder/pool.go
+package encoder
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// nvencSessionLimit is the number of simultaneous NVENC sessions allowed
+// by consumer NVIDIA drivers
+const nvencSessionLimit = 3
+
+// defaultConcurrency returns how many files are encoded at once for a codec
+// when EncodingOptions.MaxConcurrency is not set
+func defaultConcurrency(videoCodec string) int {
+	switch {
+	case strings.HasSuffix(videoCodec, "_nvenc"):
+		return nvencSessionLimit
+	case strings.HasSuffix(videoCodec, "_qsv"), strings.HasSuffix(videoCodec, "_videotoolbox"):
+		return 1
+	}
+
+	// CPU 코덱은 자체적으로 멀티스레드를 사용하므로 코어 수의 일부만 사용
+	workers := runtime.NumCPU() / 4
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// concurrency returns the number of workers to use for the batch
+func (opts *EncodingOptions) concurrency() int {
+	if opts.MaxConcurrency > 0 {
+		return opts.MaxConcurrency
+	}
+	return defaultConcurrency(opts.VideoCodec)
+}
+
+// FileFailure describes a file that could not be encoded
+type FileFailure struct {
+	Path       string       `json:"path"`
+	Error      string       `json:"error"`
+	StderrTail string       `json:"stderrtail,omitempty"`
+	Details    *EncodeError `json:"details,omitempty"`
+}
+
+// BatchResult lists the outcome of every file in a batch, in input order
+type BatchResult struct {
+	Succeeded []string      `json:"succeeded"`
+	Failed    []FileFailure `json:"failed"`
+	Skipped   []string      `json:"skipped"`
+	Cancelled []string      `json:"cancelled"`       // 사용자가 취소했거나 앞선 실패로 시작하지 않은 파일
+	Error     string        `json:"error,omitempty"` // 배치가 중간에 멈춘 이유
+}
+
+// encodeTask is a single file scheduled on the worker pool
+type encodeTask struct {
+	inputPath  string
+	outputPath string
+	options    EncodingOptions
+
+	// started is called before encoding; returning false skips the task
+	started func() bool
+	// resolved is called with the output path chosen by the conflict policy
+	resolved func(outputPath string)
+	// finished is called with the outcome, or JobCancelled if the task never ran
+	finished func(status JobStatus, err error)
+
+	// err is set for a job rejected before the batch; it is reported as failed
+	// without being encoded and does not stop the batch
+	err error
+}
+
+// newEncodeTasks creates tasks that encode every path with the same options
+func newEncodeTasks(paths []string, options EncodingOptions) ([]encodeTask, error) {
+	outputs, err := options.OutputPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]encodeTask, len(paths))
+	for i, path := range paths {
+		tasks[i] = encodeTask{inputPath: path, outputPath: outputs[i], options: options}
+	}
+	return tasks, nil
+}
+
+// outcome classifies the result of encoding a task
+func (t *encodeTask) outcome(err error) JobStatus {
+	switch {
+	case err == nil:
+		return JobCompleted
+	case errors.Is(err, ErrCancelled):
+		return JobCancelled
+	case t.options.skips(err):
+		return JobSkipped
+	default:
+		return JobFailed
+	}
+}
+
+// runPool encodes tasks with a bounded number of workers. Unless a task's
+// options continue on error, no new files are started once a file fails, and
+// the files left are reported as cancelled. Failures are reported in the
+// result rather than as an error so the frontend always receives it;
+// BatchResult.Error joins the failures that stopped the batch in input order
+// so it does not depend on scheduling.
+func (e *Encoder) runPool(ctx context.Context, tasks []encodeTask, workers int, progressCallback func(EncodingProgress)) *BatchResult {
+	if workers > len(tasks) {
+		workers = len(tasks)
+	}
+
+	// 여러 워커에서 호출되므로 콜백 직렬화
+	var callbackMu sync.Mutex
+	callback := func(progress EncodingProgress) {
+		callbackMu.Lock()
+		defer callbackMu.Unlock()
+		progressCallback(progress)
+	}
+
+	statuses := make([]JobStatus, len(tasks))
+	errs := make([]error, len(tasks))
+	taken := make([]bool, len(tasks)) // 워커가 맡은 파일
+	var stop atomic.Bool
+	indexes := make(chan int)
+
+	// 배치 전에 거부된 작업은 인코딩하지 않고 바로 실패로 보고
+	for i, task := range tasks {
+		if task.err == nil {
+			continue
+		}
+		taken[i] = true
+		statuses[i] = JobFailed
+		errs[i] = task.err
+		if task.finished != nil {
+			task.finished(JobFailed, task.err)
+		}
+		callback(EncodingProgress{
+			Filename: filepath.Base(task.inputPath),
+			Path:     task.inputPath,
+			Status:   "failed",
+			Error:    task.err.Error(),
+		})
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// 실패나 취소 직후 이미 전달된 파일은 시작하지 않음
+				if stop.Load() || ctx.Err() != nil {
+					continue
+				}
+				taken[i] = true
+				task := tasks[i]
+				// 큐에서 지워졌거나 다른 배치가 이미 시작한 작업은 큐 상태를 바꾸지 않고 보고만 함
+				if task.started != nil && !task.started() {
+					statuses[i] = JobCancelled
+					callback(EncodingProgress{
+						Filename: filepath.Base(task.inputPath),
+						Path:     task.inputPath,
+						Status:   "cancelled",
+						Error:    ErrJobChanged.Error(),
+					})
+					continue
+				}
+				err := e.encodeFile(ctx, task, callback)
+				statuses[i] = task.outcome(err)
+				errs[i] = err
+				if task.finished != nil {
+					task.finished(statuses[i], err)
+				}
+				if statuses[i] == JobFailed && !task.options.continueOnError() {
+					stop.Store(true)
+				}
+			}
+		}()
+	}
+
+	next := 0
+dispatch:
+	for next < len(tasks) && !stop.Load() {
+		if tasks[next].err != nil {
+			next++
+			continue
+		}
+		select {
+		case indexes <- next:
+			next++
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	// 시작하지 못한 파일: 사용자가 취소했거나 앞선 파일이 실패해 중단됨
+	notStarted := ErrBatchStopped
+	if ctx.Err() != nil {
+		notStarted = ErrCancelled
+	}
+	for i := range tasks {
+		if taken[i] {
+			continue
+		}
+		statuses[i] = JobCancelled
+		if tasks[i].finished != nil {
+			tasks[i].finished(JobCancelled, notStarted)
+		}
+		progress := EncodingProgress{
+			Filename: filepath.Base(tasks[i].inputPath),
+			Path:     tasks[i].inputPath,
+			Status:   "cancelled",
+		}
+		if notStarted == ErrBatchStopped {
+			progress.Error = notStarted.Error()
+		}
+		callback(progress)
+	}
+
+	result := &BatchResult{}
+	var failures []error
+	for i, task := range tasks {
+		switch statuses[i] {
+		case JobCompleted:
+			result.Succeeded = append(result.Succeeded, task.inputPath)
+		case JobSkipped:
+			result.Skipped = append(result.Skipped, task.inputPath)
+		case JobCancelled:
+			result.Cancelled = append(result.Cancelled, task.inputPath)
+		case JobFailed:
+			failure := FileFailure{
+				Path:  task.inputPath,
+				Error: errs[i].Error(),
+			}
+			if errors.As(errs[i], &failure.Details) {
+				failure.StderrTail = failure.Details.StderrTail
+			}
+			result.Failed = append(result.Failed, failure)
+			if task.err == nil && !task.options.continueOnError() {
+				failures = append(failures, errs[i])
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		result.Error = ErrCancelled.Error()
+	} else if err := errors.Join(failures...); err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+// pkg/encoder/pool_test.go
+package encoder
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunPoolCancelledBeforeStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 첫 파일을 맡은 직후, ffmpeg를 시작하기 전에 취소
+	dir := t.TempDir()
+	var tasks []encodeTask
+	for _, name := range []string{"a.mp4", "b.mp4", "c.mp4"} {
+		tasks = append(tasks, encodeTask{
+			inputPath:  filepath.Join(dir, name),
+			outputPath: filepath.Join(dir, "out", name),
+			started:    func() bool { cancel(); return true },
+		})
+	}
+
+	var events []EncodingProgress
+	result := NewEncoder(nil).runPool(ctx, tasks, 1, func(progress EncodingProgress) {
+		events = append(events, progress)
+	})
+
+	if len(result.Failed) != 0 || len(result.Cancelled) != len(tasks) {
+		t.Errorf("result = %+v, want every file cancelled", result)
+	}
+	if result.Error != ErrCancelled.Error() {
+		t.Errorf("result error = %q, want %q", result.Error, ErrCancelled.Error())
+	}
+	for _, event := range events {
+		if event.Status != "cancelled" {
+			t.Errorf("event for %s has status %s, want cancelled", event.Path, event.Status)
+		}
+	}
+}
+
+func TestRunPoolReportsJobsThatDoNotRun(t *testing.T) {
+	dir := t.TempDir()
+	var finished []JobStatus
+	tasks := []encodeTask{
+		{
+			inputPath: filepath.Join(dir, "invalid.mp4"),
+			err:       errors.New("invalid encoding options"),
+			finished:  func(status JobStatus, err error) { finished = append(finished, status) },
+		},
+		{
+			inputPath: filepath.Join(dir, "removed.mp4"),
+			started:   func() bool { return false },
+			finished:  func(status JobStatus, err error) { finished = append(finished, status) },
+		},
+	}
+
+	events := map[string]EncodingProgress{}
+	result := NewEncoder(nil).runPool(context.Background(), tasks, 1, func(progress EncodingProgress) {
+		events[filepath.Base(progress.Path)] = progress
+	})
+
+	if len(result.Failed) != 1 || result.Failed[0].Path != tasks[0].inputPath {
+		t.Errorf("failed = %+v, want the invalid job", result.Failed)
+	}
+	if len(result.Cancelled) != 1 || result.Cancelled[0] != tasks[1].inputPath {
+		t.Errorf("cancelled = %v, want the removed job", result.Cancelled)
+	}
+	// 거부된 작업은 배치를 멈추지 않음
+	if result.Error != "" {
+		t.Errorf("result error = %q, want none", result.Error)
+	}
+	if events["invalid.mp4"].Status != "failed" || events["removed.mp4"].Status != "cancelled" {
+		t.Errorf("events = %+v", events)
+	}
+	// 큐 상태는 거부된 작업만 기록하고, 바뀐 작업은 그대로 둠
+	if len(finished) != 1 || finished[0] != JobFailed {
+		t.Errorf("finished = %v, want [failed]", finished)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+// pkg/encoder/process_unix.go
+package encoder
+
+import (
+	"os"
+	"syscall"
+)
+
+// suspendProcess stops the process until resumeProcess is called
+func suspendProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGSTOP)
+}
+
+// resumeProcess continues a process stopped by suspendProcess
+func resumeProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGCONT)
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+// pkg/encoder/process_windows.go
+package encoder
+
+import (
+	"fmt"
+	"os"
+)
+
+func suspendProcess(p *os.Process) error {
+	return fmt.Errorf("pausing encoding is not supported on windows")
+}
+
+func resumeProcess(p *os.Process) error {
+	return fmt.Errorf("resuming encoding is not supported on windows")
+}
@@ -4,105 +4,381 @@ package encoder
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"io"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type EncodingProgress struct {
 	Filename string  `json:"filename"`
+	Path     string  `json:"path"` // 입력 파일 전체 경로 (PauseEncoding/ResumeEncoding에 사용)
 	Frame    int     `json:"frame"`
-	FPS      int     `json:"fps"`
+	FPS      float64 `json:"fps"`
 	Time     string  `json:"time"`
-	Size     int     `json:"size"`
+	Size     int     `json:"size"` // 현재 파일 크기 (KB)
 	Bitrate  float64 `json:"bitrate"`
 	Speed    float64 `json:"speed"`
-	Progress float64 `json:"progress"`
+	Progress float64 `json:"progress"` // 전체 진행률 (0-100, 2-pass는 패스당 50%)
 	Status   string  `json:"status"`
+	Error    string  `json:"error,omitempty"`
+
+	// 실패한 ffmpeg 실행의 상세 정보 (status가 failed일 때)
+	ErrorDetails *EncodeError `json:"errordetails,omitempty"`
+
+	Pass           int     `json:"pass"`           // 현재 패스 (1 또는 2)
+	Duration       float64 `json:"duration"`       // 입력 길이 (초)
+	ElapsedSeconds float64 `json:"elapsedseconds"` // 일시정지를 제외한 경과 시간
+	ETASeconds     float64 `json:"etaseconds"`     // 남은 예상 시간
 }
 
+// progressArgs makes ffmpeg write machine-readable progress to stdout and
+// keeps the human-readable stats line out of stderr
+var progressArgs = []string{"-progress", "pipe:1", "-nostats"}
+
+// Regexes for the human-readable stats line ffmpeg prints to stderr without
+// -nostats. Newer builds print KiB instead of kB and N/A for unknown values.
 var (
 	frameRegex   = regexp.MustCompile(`frame=\s*(\d+)`)
-	fpsRegex     = regexp.MustCompile(`fps=\s*(\d+)`)
-	timeRegex    = regexp.MustCompile(`time=(\d{2}):(\d{2}):(\d{2}\.\d{2})`)
-	sizeRegex    = regexp.MustCompile(`size=\s*(\d+)kB`)
-	bitrateRegex = regexp.MustCompile(`bitrate=\s*(\d+\.\d+)kbits/s`)
-	speedRegex   = regexp.MustCompile(`speed=\s*(\d+\.\d+)x`)
+	fpsRegex     = regexp.MustCompile(`fps=\s*(\d+(?:\.\d+)?)`)
+	timeRegex    = regexp.MustCompile(`time=(\d{2}):(\d{2}):(\d{2}(?:\.\d+)?)`)
+	sizeRegex    = regexp.MustCompile(`size=\s*(\d+)\s*(?:kB|KiB)`)
+	bitrateRegex = regexp.MustCompile(`bitrate=\s*(\d+(?:\.\d+)?)kbits/s`)
+	speedRegex   = regexp.MustCompile(`speed=\s*(\d+(?:\.\d+)?)x`)
+
+	durationRegex = regexp.MustCompile(`Duration:\s*(\d{2}):(\d{2}):(\d{2}\.\d+)`)
 )
 
+// stderrTailLines is the number of non-progress stderr lines kept for error reports
+const stderrTailLines = 20
+
+// progressClock measures how long a file has been encoding across all of its
+// passes, excluding the time it spent paused
+type progressClock struct {
+	mu       sync.Mutex
+	start    time.Time
+	pausedAt time.Time
+	paused   time.Duration
+}
+
+func newProgressClock() *progressClock {
+	return &progressClock{start: time.Now()}
+}
+
+func (c *progressClock) pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pausedAt.IsZero() {
+		c.pausedAt = time.Now()
+	}
+}
+
+func (c *progressClock) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.pausedAt.IsZero() {
+		c.paused += time.Since(c.pausedAt)
+		c.pausedAt = time.Time{}
+	}
+}
+
+// elapsed returns the active encoding time, frozen while paused
+func (c *progressClock) elapsed() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if !c.pausedAt.IsZero() {
+		now = c.pausedAt
+	}
+	return now.Sub(c.start) - c.paused
+}
+
+// passInfo identifies which ffmpeg pass of a file a ProgressReader follows
+type passInfo struct {
+	number int
+	total  int
+	clock  *progressClock
+}
+
+// singlePass returns the passInfo of a file encoded in one pass
+func singlePass() passInfo {
+	return passInfo{number: 1, total: 1, clock: newProgressClock()}
+}
+
 type ProgressReader struct {
-	callback     func(EncodingProgress)
-	filename     string
-	scanner      *bufio.Scanner
+	callback func(EncodingProgress)
+	filename string
+	pass     passInfo
+
+	mu           sync.Mutex
+	duration     float64
 	lastProgress EncodingProgress
+	paused       bool
+	tail         *lineRing
 }
 
 func NewProgressReader(callback func(EncodingProgress), filename string) *ProgressReader {
 	return &ProgressReader{
 		callback: callback,
 		filename: filename,
+		pass:     singlePass(),
+		tail:     newLineRing(stderrTailLines),
 	}
 }
 
+// SetPaused switches the reported status between paused and processing.
+// The last known progress is re-emitted so the frontend keeps its numbers.
+func (pr *ProgressReader) SetPaused(paused bool) {
+	if paused {
+		pr.pass.clock.pause()
+	} else {
+		pr.pass.clock.resume()
+	}
+
+	pr.mu.Lock()
+	pr.paused = paused
+	progress := pr.lastProgress
+	pr.mu.Unlock()
+
+	progress.Filename = pr.filename
+	progress.Status = pr.status(paused)
+	pr.callback(progress)
+}
+
+func (pr *ProgressReader) status(paused bool) string {
+	if paused {
+		return "paused"
+	}
+	return "processing"
+}
+
+// ReadProgress parses the key=value blocks ffmpeg writes with -progress.
+// Each block ends with a progress=continue or progress=end line.
 func (pr *ProgressReader) ReadProgress(reader io.Reader) {
-	pr.scanner = bufio.NewScanner(reader)
-	pr.scanner.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
-		if atEOF && len(data) == 0 {
-			return 0, nil, nil
-		}
+	scanner := bufio.NewScanner(reader)
 
-		if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
-			return i + 1, data[0:i], nil
+	var block progressBlock
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
 		}
-
-		if atEOF {
-			return len(data), data, nil
+		if block.set(key, value) {
+			pr.emit(block.progress, block.outTime)
+			block = progressBlock{}
 		}
+	}
+}
 
-		return 0, nil, nil
-	})
+// ReadDiagnostics reads ffmpeg's stderr. It picks up the input duration from
+// the header and keeps the last lines for error reports. Stats lines, printed
+// when -nostats is not in effect, are not kept.
+func (pr *ProgressReader) ReadDiagnostics(reader io.Reader) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(scanLinesCR)
 
-	for pr.scanner.Scan() {
-		line := pr.scanner.Text()
+	for scanner.Scan() {
+		line := scanner.Text()
 		if len(strings.TrimSpace(line)) == 0 {
 			continue
 		}
 
-		progress := EncodingProgress{
-			Filename: pr.filename,
-			Status:   "processing",
+		if matches := durationRegex.FindStringSubmatch(line); len(matches) > 3 {
+			pr.mu.Lock()
+			if pr.duration == 0 {
+				pr.duration = parseClock(matches[1], matches[2], matches[3])
+			}
+			pr.mu.Unlock()
 		}
 
-		// Parse progress information
-		if matches := frameRegex.FindStringSubmatch(line); len(matches) > 1 {
-			progress.Frame, _ = strconv.Atoi(matches[1])
+		if _, _, ok := parseStatsLine(line); ok {
+			continue
 		}
+		pr.appendTail(line)
+	}
+}
 
-		if matches := fpsRegex.FindStringSubmatch(line); len(matches) > 1 {
-			progress.FPS, _ = strconv.Atoi(matches[1])
-		}
+// scanLinesCR splits on both \r and \n, since ffmpeg redraws its stats line with \r
+func scanLinesCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
 
-		if matches := timeRegex.FindStringSubmatch(line); len(matches) > 1 {
-			progress.Time = matches[0][5:] // Remove "time=" prefix
-		}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[0:i], nil
+	}
 
-		if matches := sizeRegex.FindStringSubmatch(line); len(matches) > 1 {
-			progress.Size, _ = strconv.Atoi(matches[1])
-		}
+	if atEOF {
+		return len(data), data, nil
+	}
 
-		if matches := bitrateRegex.FindStringSubmatch(line); len(matches) > 1 {
-			progress.Bitrate, _ = strconv.ParseFloat(matches[1], 64)
-		}
+	return 0, nil, nil
+}
 
-		if matches := speedRegex.FindStringSubmatch(line); len(matches) > 1 {
-			progress.Speed, _ = strconv.ParseFloat(matches[1], 64)
-		}
+// progressBlock collects the values of one -progress block
+type progressBlock struct {
+	progress EncodingProgress
+	outTime  float64
+}
 
-		// Only emit progress event if we have meaningful progress data
-		if progress.Time != "" || progress.Frame > 0 {
-			pr.callback(progress)
-			pr.lastProgress = progress
+// set records a key=value pair and reports whether it ended the block
+func (b *progressBlock) set(key, value string) bool {
+	switch key {
+	case "frame":
+		b.progress.Frame, _ = strconv.Atoi(value)
+	case "fps":
+		b.progress.FPS, _ = strconv.ParseFloat(value, 64)
+	case "bitrate":
+		b.progress.Bitrate, _ = strconv.ParseFloat(strings.TrimSuffix(value, "kbits/s"), 64)
+	case "total_size":
+		if size, err := strconv.ParseInt(value, 10, 64); err == nil {
+			b.progress.Size = int(size / 1024)
+		}
+	case "out_time_us", "out_time_ms":
+		// ffmpeg 4.2 이전에는 out_time_ms만 출력하며, 이름과 달리 값은 마이크로초
+		if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+			b.outTime = float64(us) / 1e6
+			b.progress.Time = formatClock(b.outTime)
 		}
+	case "speed":
+		b.progress.Speed, _ = strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64)
+	case "progress":
+		return true
+	}
+	return false
+}
+
+// parseStatsLine parses a human-readable stats line from stderr. It returns
+// the progress, the output time in seconds, and whether the line was a stats line.
+func parseStatsLine(line string) (EncodingProgress, float64, bool) {
+	var progress EncodingProgress
+	var outTime float64
+
+	if matches := frameRegex.FindStringSubmatch(line); len(matches) > 1 {
+		progress.Frame, _ = strconv.Atoi(matches[1])
+	}
+
+	if matches := fpsRegex.FindStringSubmatch(line); len(matches) > 1 {
+		progress.FPS, _ = strconv.ParseFloat(matches[1], 64)
+	}
+
+	if matches := timeRegex.FindStringSubmatch(line); len(matches) > 3 {
+		outTime = parseClock(matches[1], matches[2], matches[3])
+		progress.Time = formatClock(outTime)
+	}
+
+	if matches := sizeRegex.FindStringSubmatch(line); len(matches) > 1 {
+		progress.Size, _ = strconv.Atoi(matches[1])
+	}
+
+	if matches := bitrateRegex.FindStringSubmatch(line); len(matches) > 1 {
+		progress.Bitrate, _ = strconv.ParseFloat(matches[1], 64)
+	}
+
+	if matches := speedRegex.FindStringSubmatch(line); len(matches) > 1 {
+		progress.Speed, _ = strconv.ParseFloat(matches[1], 64)
+	}
+
+	ok := progress.Time != "" || progress.Frame > 0 || strings.Contains(line, "time=N/A")
+	return progress, outTime, ok
+}
+
+// emit fills in the derived fields and sends the progress to the callback
+func (pr *ProgressReader) emit(progress EncodingProgress, outTime float64) {
+	pr.mu.Lock()
+	progress.Filename = pr.filename
+	progress.Status = pr.status(pr.paused)
+	pr.fillEstimates(&progress, outTime)
+	pr.lastProgress = progress
+	pr.mu.Unlock()
+
+	pr.callback(progress)
+}
+
+// fillEstimates computes the overall percentage, elapsed time and ETA.
+// Each pass of a 2-pass encode covers an equal share of the percentage.
+func (pr *ProgressReader) fillEstimates(progress *EncodingProgress, outTime float64) {
+	progress.Pass = pr.pass.number
+	progress.Duration = pr.duration
+
+	elapsed := pr.pass.clock.elapsed().Seconds()
+	progress.ElapsedSeconds = elapsed
+
+	if pr.duration <= 0 {
+		return
+	}
+
+	passFraction := outTime / pr.duration
+	passFraction = math.Max(0, math.Min(1, passFraction))
+
+	overall := (float64(pr.pass.number-1) + passFraction) / float64(pr.pass.total)
+	progress.Progress = overall * 100
+
+	if overall > 0 {
+		progress.ETASeconds = elapsed * (1 - overall) / overall
+	}
+}
+
+// parseClock converts HH, MM and SS.ss components to seconds
+func parseClock(hours, minutes, seconds string) float64 {
+	h, _ := strconv.Atoi(hours)
+	m, _ := strconv.Atoi(minutes)
+	s, _ := strconv.ParseFloat(seconds, 64)
+	return float64(h)*3600 + float64(m)*60 + s
+}
+
+// formatClock formats seconds as HH:MM:SS.ss like ffmpeg's stats line
+func formatClock(seconds float64) string {
+	h := int(seconds / 3600)
+	m := int(math.Mod(seconds, 3600) / 60)
+	return fmt.Sprintf("%02d:%02d:%05.2f", h, m, math.Mod(seconds, 60))
+}
+
+func (pr *ProgressReader) appendTail(line string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	pr.tail.add(line)
+}
+
+// StderrTail returns the last non-progress lines ffmpeg wrote to stderr
+func (pr *ProgressReader) StderrTail() string {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	return strings.Join(pr.tail.lines(), "\n")
+}
+
+// lineRing is a fixed-size ring buffer that keeps the most recent lines
+type lineRing struct {
+	buf  []string
+	next int
+	full bool
+}
+
+func newLineRing(size int) *lineRing {
+	return &lineRing{buf: make([]string, size)}
+}
+
+func (r *lineRing) add(line string) {
+	r.buf[r.next] = line
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// lines returns the buffered lines from oldest to newest
+func (r *lineRing) lines() []string {
+	if !r.full {
+		return append([]string(nil), r.buf[:r.next]...)
 	}
+	return append(append([]string(nil), r.buf[r.next:]...), r.buf[:r.next]...)
 }
@@ -0,0 +1,259 @@
+// pkg/encoder/progress_test.go
+package encoder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStatsLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    EncodingProgress
+		outTime float64
+		ok      bool
+	}{
+		{
+			name:    "ffmpeg 4 kB",
+			line:    "frame=  240 fps= 48 q=28.0 size=    1024kB time=00:00:08.00 bitrate=1048.6kbits/s speed=1.61x",
+			want:    EncodingProgress{Frame: 240, FPS: 48, Time: "00:00:08.00", Size: 1024, Bitrate: 1048.6, Speed: 1.61},
+			outTime: 8,
+			ok:      true,
+		},
+		{
+			name:    "ffmpeg 7 KiB and fractional fps",
+			line:    "frame= 1502 fps=59.94 q=29.0 size=   12544KiB time=00:01:02.56 bitrate=1642kbits/s speed=2.5x",
+			want:    EncodingProgress{Frame: 1502, FPS: 59.94, Time: "00:01:02.56", Size: 12544, Bitrate: 1642, Speed: 2.5},
+			outTime: 62.56,
+			ok:      true,
+		},
+		{
+			name: "first line with N/A values",
+			line: "frame=    0 fps=0.0 q=0.0 size=       0KiB time=N/A bitrate=N/A speed=N/A",
+			want: EncodingProgress{Size: 0},
+			ok:   true,
+		},
+		{
+			name:    "final Lsize line",
+			line:    "frame= 2400 fps=120 q=-1.0 Lsize=   20480KiB time=00:01:40.00 bitrate=1677.7kbits/s speed=5.01x",
+			want:    EncodingProgress{Frame: 2400, FPS: 120, Time: "00:01:40.00", Size: 20480, Bitrate: 1677.7, Speed: 5.01},
+			outTime: 100,
+			ok:      true,
+		},
+		{
+			name: "header line",
+			line: "  Duration: 00:01:40.00, start: 0.000000, bitrate: 5120 kb/s",
+			ok:   false,
+		},
+		{
+			name: "error line",
+			line: "Unknown encoder 'libfoo'",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, outTime, ok := parseStatsLine(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("progress = %+v, want %+v", got, tt.want)
+			}
+			if outTime != tt.outTime {
+				t.Errorf("outTime = %v, want %v", outTime, tt.outTime)
+			}
+		})
+	}
+}
+
+func TestReadProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		duration float64
+		want     []EncodingProgress
+	}{
+		{
+			name: "continue and end blocks",
+			output: `frame=120
+fps=59.94
+stream_0_0_q=28.0
+bitrate=1048.6kbits/s
+total_size=1048576
+out_time_us=5000000
+out_time_ms=5000000
+out_time=00:00:05.000000
+dup_frames=0
+drop_frames=0
+speed=2.01x
+progress=continue
+frame=240
+fps=60.00
+stream_0_0_q=-1.0
+bitrate=1100.0kbits/s
+total_size=2097152
+out_time_us=10000000
+out_time_ms=10000000
+out_time=00:00:10.000000
+dup_frames=0
+drop_frames=0
+speed=2.00x
+progress=end
+`,
+			duration: 10,
+			want: []EncodingProgress{
+				{Frame: 120, FPS: 59.94, Time: "00:00:05.00", Size: 1024, Bitrate: 1048.6, Speed: 2.01, Progress: 50},
+				{Frame: 240, FPS: 60, Time: "00:00:10.00", Size: 2048, Bitrate: 1100, Speed: 2, Progress: 100},
+			},
+		},
+		{
+			name: "N/A values before the first frame",
+			output: `frame=0
+fps=0.00
+stream_0_0_q=0.0
+bitrate=N/A
+total_size=N/A
+out_time_us=N/A
+out_time_ms=N/A
+out_time=N/A
+dup_frames=0
+drop_frames=0
+speed=N/A
+progress=continue
+`,
+			duration: 10,
+			want:     []EncodingProgress{{}},
+		},
+		{
+			name: "unknown duration",
+			output: `frame=30
+fps=30.00
+total_size=4096
+out_time_us=1000000
+speed=1.00x
+progress=continue
+`,
+			want: []EncodingProgress{
+				{Frame: 30, FPS: 30, Time: "00:00:01.00", Size: 4, Speed: 1},
+			},
+		},
+		{
+			name: "ffmpeg 4.1 without out_time_us",
+			output: `frame=120
+fps=30.00
+total_size=1048576
+out_time_ms=4000000
+out_time=00:00:04.000000
+speed=1.50x
+progress=continue
+`,
+			duration: 8,
+			want: []EncodingProgress{
+				{Frame: 120, FPS: 30, Time: "00:00:04.00", Size: 1024, Speed: 1.5, Progress: 50},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []EncodingProgress
+			pr := NewProgressReader(func(p EncodingProgress) { got = append(got, p) }, "input.mp4")
+			pr.duration = tt.duration
+			pr.ReadProgress(strings.NewReader(tt.output))
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d events, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				p := got[i]
+				if p.Filename != "input.mp4" || p.Status != "processing" || p.Pass != 1 {
+					t.Errorf("event %d: filename/status/pass = %q/%q/%d", i, p.Filename, p.Status, p.Pass)
+				}
+				p.Filename, p.Status, p.Pass, p.Duration = "", "", 0, 0
+				p.ElapsedSeconds, p.ETASeconds = 0, 0
+				if p != want {
+					t.Errorf("event %d = %+v, want %+v", i, p, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadDiagnostics(t *testing.T) {
+	stderr := "ffmpeg version 7.0 Copyright (c) 2000-2024 the FFmpeg developers\n" +
+		"Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'input.mp4':\n" +
+		"  Duration: 00:01:40.00, start: 0.000000, bitrate: 5120 kb/s\n" +
+		"frame=  240 fps= 48 q=28.0 size=    1024KiB time=00:00:08.00 bitrate=1048.6kbits/s speed=1.61x\r" +
+		"[libx264 @ 0x7f] error while encoding frame\n" +
+		"Conversion failed!\n"
+
+	pr := NewProgressReader(func(EncodingProgress) {}, "input.mp4")
+	pr.ReadDiagnostics(strings.NewReader(stderr))
+
+	if pr.duration != 100 {
+		t.Errorf("duration = %v, want 100", pr.duration)
+	}
+
+	tail := pr.StderrTail()
+	if strings.Contains(tail, "frame=") {
+		t.Errorf("stats line kept in tail:\n%s", tail)
+	}
+	if !strings.HasSuffix(tail, "[libx264 @ 0x7f] error while encoding frame\nConversion failed!") {
+		t.Errorf("unexpected tail:\n%s", tail)
+	}
+}
+
+func TestFillEstimatesTwoPass(t *testing.T) {
+	tests := []struct {
+		pass    int
+		outTime float64
+		want    float64
+	}{
+		{pass: 1, outTime: 0, want: 0},
+		{pass: 1, outTime: 50, want: 25},
+		{pass: 1, outTime: 100, want: 50},
+		{pass: 2, outTime: 50, want: 75},
+		{pass: 2, outTime: 120, want: 100},
+	}
+
+	for _, tt := range tests {
+		pr := NewProgressReader(func(EncodingProgress) {}, "input.mp4")
+		pr.pass = passInfo{number: tt.pass, total: 2, clock: newProgressClock()}
+		pr.duration = 100
+
+		var progress EncodingProgress
+		pr.fillEstimates(&progress, tt.outTime)
+		if progress.Progress != tt.want {
+			t.Errorf("pass %d at %vs: progress = %v, want %v", tt.pass, tt.outTime, progress.Progress, tt.want)
+		}
+	}
+}
+
+func TestLineRing(t *testing.T) {
+	tests := []struct {
+		size  int
+		added []string
+		want  []string
+	}{
+		{size: 3, added: nil, want: []string{}},
+		{size: 3, added: []string{"a", "b"}, want: []string{"a", "b"}},
+		{size: 3, added: []string{"a", "b", "c"}, want: []string{"a", "b", "c"}},
+		{size: 3, added: []string{"a", "b", "c", "d", "e"}, want: []string{"c", "d", "e"}},
+	}
+
+	for _, tt := range tests {
+		r := newLineRing(tt.size)
+		for _, line := range tt.added {
+			r.add(line)
+		}
+		if got := strings.Join(r.lines(), ","); got != strings.Join(tt.want, ",") {
+			t.Errorf("after %v: lines = %q, want %q", tt.added, got, strings.Join(tt.want, ","))
+		}
+	}
+}
@@ -0,0 +1,327 @@
+// pkg/encoder/queue.go
+package encoder
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"encoder/pkg/fileutil"
+)
+
+type JobStatus string
+
+const (
+	JobQueued     JobStatus = "queued"
+	JobProcessing JobStatus = "processing"
+	JobCompleted  JobStatus = "completed"
+	JobFailed     JobStatus = "failed"
+	JobCancelled  JobStatus = "cancelled"
+	JobSkipped    JobStatus = "skipped"
+)
+
+// Job is a single input file waiting in, or finished by, the queue
+type Job struct {
+	ID         string          `json:"id"`
+	InputPath  string          `json:"inputpath"`
+	OutputPath string          `json:"outputpath"`
+	Options    EncodingOptions `json:"options"`
+	Status     JobStatus       `json:"status"`
+	Attempts   int             `json:"attempts"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"createdat"`
+	UpdatedAt  time.Time       `json:"updatedat"`
+}
+
+// Queue is an ordered list of jobs persisted to a JSON file after every change
+type Queue struct {
+	mu   sync.Mutex
+	path string
+	jobs []*Job
+}
+
+// DefaultQueuePath returns the queue file location in the user config directory
+func DefaultQueuePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "encoder", "queue.json"), nil
+}
+
+// LoadQueue reads the queue stored at path. A missing file yields an empty queue.
+// Jobs that were processing when the app exited are put back in the queue.
+// A file that cannot be parsed is renamed to path.bad so it is not overwritten;
+// if it cannot be read or moved, the returned queue is not saved to disk.
+func LoadQueue(path string) (*Queue, error) {
+	q := &Queue{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		q.path = ""
+		return q, fmt.Errorf("failed to read queue file (%s), changes will not be saved: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &q.jobs); err != nil {
+		q.jobs = nil
+		badPath, renameErr := fileutil.MoveAside(path)
+		if renameErr != nil {
+			q.path = ""
+			return q, fmt.Errorf("failed to parse queue file (%s), changes will not be saved: %w", path, err)
+		}
+		return q, fmt.Errorf("failed to parse queue file, moved it to %s: %w", badPath, err)
+	}
+
+	// 종료 시 진행 중이던 작업은 다시 대기 상태로
+	for _, job := range q.jobs {
+		if job.Status == JobProcessing {
+			job.Status = JobQueued
+		}
+	}
+
+	return q, nil
+}
+
+// Add appends one job per input path and returns the created jobs.
+// Output paths are resolved for the paths as one batch.
+func (q *Queue) Add(paths []string, options EncodingOptions) ([]Job, error) {
+	outputs, err := options.OutputPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	added := make([]Job, 0, len(paths))
+	for i, path := range paths {
+		id, err := newJobID()
+		if err != nil {
+			return nil, err
+		}
+		job := &Job{
+			ID:         id,
+			InputPath:  path,
+			OutputPath: outputs[i],
+			Options:    options,
+			Status:     JobQueued,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		q.jobs = append(q.jobs, job)
+		added = append(added, *job)
+	}
+
+	return added, q.save()
+}
+
+// List returns a snapshot of all jobs in queue order
+func (q *Queue) List() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]Job, len(q.jobs))
+	for i, job := range q.jobs {
+		jobs[i] = *job
+	}
+	return jobs
+}
+
+// Pending returns the IDs of all queued jobs in queue order
+func (q *Queue) Pending() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var ids []string
+	for _, job := range q.jobs {
+		if job.Status == JobQueued {
+			ids = append(ids, job.ID)
+		}
+	}
+	return ids
+}
+
+// PartialPaths returns the partial files of jobs that have not finished,
+// used to sweep files left behind by a previous session
+func (q *Queue) PartialPaths() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var paths []string
+	for _, job := range q.jobs {
+		if job.OutputPath != "" && (job.Status == JobQueued || job.Status == JobProcessing) {
+			paths = append(paths, partialPath(job.OutputPath))
+		}
+	}
+	return paths
+}
+
+// Move places the job at the given position in the queue
+func (q *Queue) Move(id string, index int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	from := q.indexOf(id)
+	if from < 0 {
+		return fmt.Errorf("job not found: %s", id)
+	}
+	if index < 0 || index >= len(q.jobs) {
+		return fmt.Errorf("index %d out of range [0-%d]", index, len(q.jobs)-1)
+	}
+
+	job := q.jobs[from]
+	q.jobs = append(q.jobs[:from], q.jobs[from+1:]...)
+	q.jobs = append(q.jobs[:index], append([]*Job{job}, q.jobs[index:]...)...)
+
+	return q.save()
+}
+
+// Remove deletes a job that is not currently being encoded
+func (q *Queue) Remove(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := q.indexOf(id)
+	if i < 0 {
+		return fmt.Errorf("job not found: %s", id)
+	}
+	if q.jobs[i].Status == JobProcessing {
+		return fmt.Errorf("cannot remove a job in progress: %s", id)
+	}
+
+	q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+	return q.save()
+}
+
+// ClearFinished removes every completed job
+func (q *Queue) ClearFinished() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := q.jobs[:0]
+	for _, job := range q.jobs {
+		if job.Status != JobCompleted {
+			jobs = append(jobs, job)
+		}
+	}
+	q.jobs = jobs
+	return q.save()
+}
+
+// Retry puts a finished job back in the queue
+func (q *Queue) Retry(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := q.indexOf(id)
+	if i < 0 {
+		return fmt.Errorf("job not found: %s", id)
+	}
+	job := q.jobs[i]
+	if job.Status == JobProcessing {
+		return fmt.Errorf("job is already in progress: %s", id)
+	}
+
+	job.Status = JobQueued
+	job.Error = ""
+	job.UpdatedAt = time.Now()
+	return q.save()
+}
+
+// start marks a queued job as processing. It returns nil if the job was
+// removed or changed since the run was scheduled.
+func (q *Queue) start(id string) *Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := q.indexOf(id)
+	if i < 0 || q.jobs[i].Status != JobQueued {
+		return nil
+	}
+
+	job := q.jobs[i]
+	job.Status = JobProcessing
+	job.Attempts++
+	job.UpdatedAt = time.Now()
+	q.save()
+
+	started := *job
+	return &started
+}
+
+// setOutput records the output path a job is actually written to, which
+// differs from the planned one when the rename conflict policy picked a free name
+func (q *Queue) setOutput(id string, outputPath string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := q.indexOf(id)
+	if i < 0 || q.jobs[i].OutputPath == outputPath {
+		return
+	}
+	q.jobs[i].OutputPath = outputPath
+	q.save()
+}
+
+// finish records the result of a job
+func (q *Queue) finish(id string, status JobStatus, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := q.indexOf(id)
+	if i < 0 {
+		return
+	}
+
+	job := q.jobs[i]
+	job.Status = status
+	job.Error = ""
+	if err != nil && !errors.Is(err, ErrCancelled) {
+		job.Error = err.Error()
+	}
+	job.UpdatedAt = time.Now()
+	q.save()
+}
+
+func (q *Queue) indexOf(id string) int {
+	for i, job := range q.jobs {
+		if job.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// save writes the queue to disk
+func (q *Queue) save() error {
+	if q.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(q.jobs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode queue: %w", err)
+	}
+	if err := fileutil.WriteFile(q.path, data); err != nil {
+		return fmt.Errorf("failed to save queue (%s): %w", q.path, err)
+	}
+	return nil
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,122 @@
+// pkg/encoder/template.go
+package encoder
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"encoder/pkg/video"
+)
+
+// templateTokenRegex matches {token} placeholders in OutputTemplate
+var templateTokenRegex = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// 출력 파일 이름 템플릿에서 사용할 수 있는 토큰
+var templateTokens = map[string]bool{
+	"name":      true, // 확장자를 제외한 입력 파일 이름
+	"ext":       true, // 입력 파일 확장자 (점 제외)
+	"codec":     true, // 비디오 코덱
+	"crf":       true, // CRF 값 (CRF 모드에서만 사용 가능)
+	"bitrate":   true, // 비트레이트 kbps (비트레이트 모드에서만 사용 가능)
+	"width":     true, // 출력 너비
+	"height":    true, // 출력 높이
+	"date":      true, // 인코딩 날짜 (YYYY-MM-DD)
+	"parentdir": true, // 입력 파일의 상위 디렉토리 이름
+	"index":     true, // 배치 내 순서 (1부터 시작)
+}
+
+// templateContext holds the per-file values an OutputTemplate is resolved with
+type templateContext struct {
+	index    int
+	count    int
+	date     string
+	metadata *video.VideoMetadata
+}
+
+// validateTemplate checks that OutputTemplate only uses known tokens that
+// have a value in the quality mode, and that it names a file rather than a path.
+// It must run after the quality mode defaults are filled in.
+func (opts *EncodingOptions) validateTemplate() error {
+	if opts.OutputTemplate == "" {
+		return nil
+	}
+
+	// 출력 디렉토리 밖에 쓰지 않도록 경로 구분자와 상위 디렉토리 참조 금지
+	if strings.ContainsAny(opts.OutputTemplate, `/\`) || strings.Contains(opts.OutputTemplate, "..") {
+		return fmt.Errorf("output template must be a file name without path separators or '..': %s", opts.OutputTemplate)
+	}
+
+	for _, match := range templateTokenRegex.FindAllStringSubmatch(opts.OutputTemplate, -1) {
+		if !templateTokens[match[1]] {
+			return fmt.Errorf("unknown output template token: %s", match[0])
+		}
+		switch {
+		case match[1] == "crf" && opts.QualityMode != QualityModeCRF:
+			return fmt.Errorf("output template token {crf} is only available in crf quality mode")
+		case match[1] == "bitrate" && opts.QualityMode != QualityModeBitrate:
+			return fmt.Errorf("output template token {bitrate} is only available in bitrate quality mode")
+		}
+	}
+	return nil
+}
+
+// templateNeedsProbe reports whether the template refers to the source
+// dimensions, which are only known after probing the input
+func (opts *EncodingOptions) templateNeedsProbe() bool {
+	if opts.resizes() {
+		return false
+	}
+	return strings.Contains(opts.OutputTemplate, "{width}") ||
+		strings.Contains(opts.OutputTemplate, "{height}")
+}
+
+// expandTemplate resolves OutputTemplate for inputPath. The output extension
+// is not part of the template and is appended by the caller. It fails if the
+// result is empty or a token value would turn the name into a path.
+func (opts *EncodingOptions) expandTemplate(inputPath string, tc templateContext) (string, error) {
+	filename := filepath.Base(inputPath)
+	ext := filepath.Ext(filename)
+
+	// 크기 조정을 하지 않으면 (너비나 높이만 지정한 경우 포함) 원본 크기 사용
+	width, height := opts.Width, opts.Height
+	if !opts.resizes() && tc.metadata != nil {
+		width, height = tc.metadata.Width, tc.metadata.Height
+	}
+
+	var crf, bitrate string
+	switch opts.QualityMode {
+	case QualityModeCRF:
+		crf = strconv.Itoa(opts.QualityValue)
+	case QualityModeBitrate:
+		bitrate = strconv.Itoa(opts.QualityValue)
+	}
+
+	digits := len(strconv.Itoa(tc.count))
+	values := map[string]string{
+		"name":      strings.TrimSuffix(filename, ext),
+		"ext":       strings.TrimPrefix(ext, "."),
+		"codec":     opts.VideoCodec,
+		"crf":       crf,
+		"bitrate":   bitrate,
+		"width":     strconv.Itoa(width),
+		"height":    strconv.Itoa(height),
+		"date":      tc.date,
+		"parentdir": filepath.Base(filepath.Dir(inputPath)),
+		"index":     fmt.Sprintf("%0*d", digits, tc.index+1),
+	}
+
+	name := templateTokenRegex.ReplaceAllStringFunc(opts.OutputTemplate, func(token string) string {
+		return values[token[1:len(token)-1]]
+	})
+
+	switch {
+	case strings.TrimSpace(name) == "":
+		return "", fmt.Errorf("output template %q gives an empty file name for %s", opts.OutputTemplate, inputPath)
+	case strings.ContainsAny(name, `/\`) || name == "." || name == "..":
+		return "", fmt.Errorf("output template %q gives an invalid file name for %s: %s", opts.OutputTemplate, inputPath, name)
+	}
+	return name, nil
+}
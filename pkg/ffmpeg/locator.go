@@ -0,0 +1,123 @@
+// pkg/ffmpeg/locator.go
+package ffmpeg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// 실행 파일 경로를 지정하는 환경 변수
+const (
+	EnvFFmpeg  = "ENCODER_FFMPEG"
+	EnvFFprobe = "ENCODER_FFPROBE"
+)
+
+var (
+	mu           sync.RWMutex
+	explicitPath = map[string]string{}
+)
+
+// SetFFmpegPath sets the ffmpeg binary to use. An empty path restores the default lookup.
+func SetFFmpegPath(path string) {
+	setPath("ffmpeg", path)
+}
+
+// SetFFprobePath sets the ffprobe binary to use. An empty path restores the default lookup.
+func SetFFprobePath(path string) {
+	setPath("ffprobe", path)
+}
+
+func setPath(tool, path string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if path == "" {
+		delete(explicitPath, tool)
+		return
+	}
+	explicitPath[tool] = path
+}
+
+// FFmpegPath returns the location of the ffmpeg binary
+func FFmpegPath() (string, error) {
+	return locate("ffmpeg", EnvFFmpeg)
+}
+
+// FFprobePath returns the location of the ffprobe binary
+func FFprobePath() (string, error) {
+	return locate("ffprobe", EnvFFprobe)
+}
+
+// locate finds a tool, checking in order: the path set by the app, the
+// environment variable, PATH, and a bin/ directory next to the executable
+func locate(tool, envVar string) (string, error) {
+	mu.RLock()
+	path := explicitPath[tool]
+	mu.RUnlock()
+
+	if path != "" {
+		if !isExecutable(path) {
+			return "", fmt.Errorf("%s not found at configured path: %s", tool, path)
+		}
+		return path, nil
+	}
+
+	if path := os.Getenv(envVar); path != "" {
+		if !isExecutable(path) {
+			return "", fmt.Errorf("%s not found at %s: %s", tool, envVar, path)
+		}
+		return path, nil
+	}
+
+	if path, err := exec.LookPath(tool); err == nil {
+		return path, nil
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		name := tool
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		path := filepath.Join(filepath.Dir(exe), "bin", name)
+		if isExecutable(path) {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s is not installed: not found in PATH or the bundled bin directory", tool)
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+// Command returns a command running ffmpeg with args
+func Command(ctx context.Context, args ...string) (*exec.Cmd, error) {
+	path, err := FFmpegPath()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	hideWindow(cmd)
+	return cmd, nil
+}
+
+// ProbeCommand returns a command running ffprobe with args
+func ProbeCommand(ctx context.Context, args ...string) (*exec.Cmd, error) {
+	path, err := FFprobePath()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	hideWindow(cmd)
+	return cmd, nil
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+// pkg/ffmpeg/process_unix.go
+package ffmpeg
+
+import "os/exec"
+
+// hideWindow is a no-op outside windows, where child processes get no console window
+func hideWindow(cmd *exec.Cmd) {}
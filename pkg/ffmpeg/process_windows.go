@@ -0,0 +1,20 @@
+//go:build windows
+
+// pkg/ffmpeg/process_windows.go
+package ffmpeg
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// createNoWindow is the CREATE_NO_WINDOW process creation flag
+const createNoWindow = 0x08000000
+
+// hideWindow keeps a console window from opening for every ffmpeg and ffprobe run
+func hideWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: createNoWindow,
+	}
+}
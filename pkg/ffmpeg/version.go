@@ -0,0 +1,160 @@
+// pkg/ffmpeg/version.go
+package ffmpeg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MinVersion is the oldest ffmpeg release the encoder is tested with
+var MinVersion = Version{Major: 4, Minor: 0}
+
+// minLibAVCodec is the libavcodec major version shipped with MinVersion,
+// used to check git builds that do not carry a release number
+const minLibAVCodec = 58
+
+type Version struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less reports whether v is older than other
+func (v Version) Less(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Patch < other.Patch
+}
+
+// Info describes the ffmpeg build found on the system
+type Info struct {
+	Path          string   `json:"path"`
+	VersionString string   `json:"versionString"` // 예: 6.1.1-3ubuntu5, N-113000-g1234abcd
+	Version       Version  `json:"version"`       // 릴리스 버전 (git 빌드는 0.0.0)
+	LibAVCodec    int      `json:"libavcodec"`    // libavcodec 주 버전
+	Configuration []string `json:"configuration"` // 빌드 설정 (--enable-libx264 등)
+	Warning       string   `json:"warning,omitempty"`
+}
+
+// HasConfiguration reports whether ffmpeg was built with the given flag, e.g. --enable-libx265
+func (i *Info) HasConfiguration(flag string) bool {
+	for _, c := range i.Configuration {
+		if c == flag {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	versionLineRegex    = regexp.MustCompile(`(?m)^ffmpeg version (\S+)`)
+	releaseVersionRegex = regexp.MustCompile(`^n?(\d+)\.(\d+)(?:\.(\d+))?`)
+	libavcodecRegex     = regexp.MustCompile(`(?m)^\s*libavcodec\s+(\d+)\.`)
+	configurationRegex  = regexp.MustCompile(`(?m)^\s*configuration:(.*)$`)
+)
+
+var (
+	infoMu    sync.Mutex
+	infoCache = map[string]*Info{}
+)
+
+// GetInfo runs ffmpeg -version and parses the result. Results are cached per binary path.
+func GetInfo() (*Info, error) {
+	path, err := FFmpegPath()
+	if err != nil {
+		return nil, err
+	}
+
+	infoMu.Lock()
+	defer infoMu.Unlock()
+
+	if info, ok := infoCache[path]; ok {
+		return info, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cmd, err := Command(ctx, "-version")
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s -version: %w", path, err)
+	}
+
+	info, err := parseVersionOutput(string(output))
+	if err != nil {
+		return nil, err
+	}
+	info.Path = path
+	infoCache[path] = info
+	return info, nil
+}
+
+// Check returns the ffmpeg info, or an error if ffmpeg is missing or older
+// than MinVersion. Builds whose version cannot be determined are accepted
+// with a warning.
+func Check() (*Info, error) {
+	info, err := GetInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Version != (Version{}) {
+		if info.Version.Less(MinVersion) {
+			return info, fmt.Errorf("ffmpeg %s is too old: version %d.%d or newer is required (%s)",
+				info.VersionString, MinVersion.Major, MinVersion.Minor, info.Path)
+		}
+		return info, nil
+	}
+
+	if info.LibAVCodec > 0 && info.LibAVCodec < minLibAVCodec {
+		return info, fmt.Errorf("ffmpeg build %s is too old: libavcodec %d or newer is required (%s)",
+			info.VersionString, minLibAVCodec, info.Path)
+	}
+	return info, nil
+}
+
+func parseVersionOutput(output string) (*Info, error) {
+	matches := versionLineRegex.FindStringSubmatch(output)
+	if len(matches) < 2 {
+		return nil, fmt.Errorf("unrecognized ffmpeg -version output")
+	}
+
+	info := &Info{VersionString: matches[1]}
+
+	if m := releaseVersionRegex.FindStringSubmatch(info.VersionString); len(m) > 2 {
+		info.Version.Major, _ = strconv.Atoi(m[1])
+		info.Version.Minor, _ = strconv.Atoi(m[2])
+		info.Version.Patch, _ = strconv.Atoi(m[3])
+	}
+
+	if m := libavcodecRegex.FindStringSubmatch(output); len(m) > 1 {
+		info.LibAVCodec, _ = strconv.Atoi(m[1])
+	}
+
+	if m := configurationRegex.FindStringSubmatch(output); len(m) > 1 {
+		info.Configuration = strings.Fields(m[1])
+	}
+
+	if info.Version == (Version{}) {
+		info.Warning = fmt.Sprintf("could not determine the release of ffmpeg build %s", info.VersionString)
+	}
+
+	return info, nil
+}
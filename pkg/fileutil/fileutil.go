@@ -0,0 +1,61 @@
+// pkg/fileutil/fileutil.go
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteFile writes data to a temporary file next to path and renames it into
+// place, so a crash never leaves a half-written file. Missing directories are created.
+func WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	return nil
+}
+
+// MoveAside renames a file that could not be parsed to path.bad, so saving
+// new contents does not destroy what the user may still want to recover
+func MoveAside(path string) (string, error) {
+	badPath := path + ".bad"
+	if err := os.Rename(path, badPath); err != nil {
+		return "", err
+	}
+	return badPath, nil
+}
+
+// NextFreePath returns path if taken reports it unused, and otherwise appends
+// (1), (2), ... to the file name until it finds a free one
+func NextFreePath(path string, taken func(string) bool) string {
+	if !taken(path) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// Exists reports whether something may exist at path. Paths that cannot be
+// checked count as existing so they are never overwritten.
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
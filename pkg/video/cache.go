@@ -0,0 +1,117 @@
+// pkg/video/cache.go
+package video
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"encoder/pkg/fileutil"
+)
+
+// cacheEntry is the probe result of a file as it was when probed
+type cacheEntry struct {
+	Size     int64          `json:"size"`
+	ModTime  time.Time      `json:"modtime"`
+	Metadata *VideoMetadata `json:"metadata"`
+}
+
+// ProbeCache stores ffprobe results on disk, keyed by path. An entry is only
+// used while the file keeps the size and modification time it was probed with.
+type ProbeCache struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]cacheEntry
+	dirty   bool
+}
+
+// DefaultProbeCachePath returns the probe cache location in the user cache directory
+func DefaultProbeCachePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "encoder", "probe-cache.json"), nil
+}
+
+// LoadProbeCache reads the cache stored at path. A missing or unreadable file
+// yields an empty cache, since every entry can be probed again.
+func LoadProbeCache(path string) (*ProbeCache, error) {
+	c := &ProbeCache{path: path, entries: map[string]cacheEntry{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, fmt.Errorf("failed to read probe cache (%s): %w", path, err)
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		c.entries = map[string]cacheEntry{}
+		return c, fmt.Errorf("failed to parse probe cache (%s): %w", path, err)
+	}
+	return c, nil
+}
+
+// Get returns the cached metadata of path if the file has not changed since it was probed
+func (c *ProbeCache) Get(path string, info os.FileInfo) (*VideoMetadata, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[path]
+	if !ok || entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+		return nil, false
+	}
+	metadata := *entry.Metadata
+	return &metadata, true
+}
+
+// Put stores the metadata of path along with the file's current size and modification time
+func (c *ProbeCache) Put(path string, info os.FileInfo, metadata *VideoMetadata) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[path] = cacheEntry{Size: info.Size(), ModTime: info.ModTime(), Metadata: metadata}
+	c.dirty = true
+}
+
+// Save writes the cache to disk if it changed, dropping entries for files that no longer exist
+func (c *ProbeCache) Save() error {
+	if c == nil || c.path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+	for path := range c.entries {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			delete(c.entries, path)
+		}
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode probe cache: %w", err)
+	}
+	if err := fileutil.WriteFile(c.path, data); err != nil {
+		return fmt.Errorf("failed to save probe cache (%s): %w", c.path, err)
+	}
+	c.dirty = false
+	return nil
+}
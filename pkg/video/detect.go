@@ -0,0 +1,222 @@
+// pkg/video/detect.go
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"encoder/pkg/ffmpeg"
+)
+
+// SkippedFile is a file that was found while scanning but not treated as a video
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// 확장자로 바로 비디오로 판단하는 목록 (AddVideoExtensions로 추가 가능)
+var (
+	extensionsMu        sync.RWMutex
+	supportedExtensions = map[string]bool{
+		".mp4":  true,
+		".m4v":  true,
+		".avi":  true,
+		".mov":  true,
+		".mkv":  true,
+		".wmv":  true,
+		".flv":  true,
+		".webm": true,
+		".ts":   true,
+		".mts":  true,
+		".m2ts": true,
+		".3gp":  true,
+		".mxf":  true,
+		".vob":  true,
+		".mpg":  true,
+		".mpeg": true,
+	}
+)
+
+// 다른 용도로도 흔히 쓰이는 확장자 (.ts/.mts는 TypeScript 소스)
+// 이 확장자는 파일 내용이 MPEG-TS일 때만 비디오로 판단
+var ambiguousExtensions = map[string]bool{
+	".ts":  true,
+	".mts": true,
+}
+
+// 내용을 확인하지 않고 건너뛰는 확장자 (이미지, 오디오, 문서, 자막 등)
+// ffprobe는 이미지도 비디오 스트림으로 보고하므로 미리 제외
+var ignoredExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".bmp": true, ".tif": true, ".tiff": true, ".heic": true, ".webp": true,
+	".mp3": true, ".wav": true, ".aac": true, ".flac": true, ".m4a": true, ".ogg": true, ".opus": true,
+	".txt": true, ".pdf": true, ".doc": true, ".docx": true, ".xml": true, ".json": true, ".log": true, ".ini": true, ".db": true,
+	".srt": true, ".ass": true, ".vtt": true,
+	".zip": true, ".rar": true, ".7z": true,
+}
+
+// AddVideoExtensions adds extensions that are always treated as video.
+// Extensions may be given with or without the leading dot.
+func AddVideoExtensions(extensions ...string) {
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		supportedExtensions[ext] = true
+	}
+}
+
+func hasVideoExtension(path string) bool {
+	extensionsMu.RLock()
+	defer extensionsMu.RUnlock()
+
+	return supportedExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// IsVideoFile checks if the file is a video file
+func IsVideoFile(path string) bool {
+	ok, _ := DetectVideo(path)
+	return ok
+}
+
+// DetectVideo reports whether path is a video file, and if not, why. Known
+// video extensions are accepted as is, except ambiguous ones like .ts which
+// must also look like a transport stream; other files are identified by their
+// leading bytes and, failing that, by asking ffprobe for a video stream.
+func DetectVideo(path string) (bool, string) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ambiguousExtensions[ext] {
+		header, err := readHeader(path)
+		if err != nil {
+			return false, fmt.Sprintf("failed to read file: %v", err)
+		}
+		switch sniffContainer(header) {
+		case "mpegts", "m2ts":
+			return true, ""
+		}
+		return false, fmt.Sprintf("not an MPEG transport stream (%s)", ext)
+	}
+
+	if hasVideoExtension(path) {
+		return true, ""
+	}
+
+	if ignoredExtensions[ext] {
+		return false, fmt.Sprintf("not a video file type (%s)", ext)
+	}
+
+	header, err := readHeader(path)
+	if err != nil {
+		return false, fmt.Sprintf("failed to read file: %v", err)
+	}
+	if len(header) == 0 {
+		return false, "empty file"
+	}
+	if sniffContainer(header) != "" {
+		return true, ""
+	}
+
+	return probeHasVideo(path)
+}
+
+// sniffLength is how many leading bytes are read to identify a container
+const sniffLength = 512
+
+func readHeader(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	header := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return header[:n], nil
+}
+
+// sniffContainer identifies common video containers by their magic bytes.
+// It returns an empty string if the header matches none of them.
+func sniffContainer(header []byte) string {
+	hasAt := func(offset int, magic []byte) bool {
+		return len(header) >= offset+len(magic) && bytes.Equal(header[offset:offset+len(magic)], magic)
+	}
+
+	switch {
+	case hasAt(4, []byte("ftyp")), hasAt(4, []byte("moov")), hasAt(4, []byte("mdat")), hasAt(4, []byte("wide")):
+		return "isobmff" // mp4, mov, m4v, 3gp
+	case hasAt(0, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "matroska" // mkv, webm
+	case hasAt(0, []byte("RIFF")) && hasAt(8, []byte("AVI ")):
+		return "avi"
+	case hasAt(0, []byte("FLV")):
+		return "flv"
+	case hasAt(0, []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11}):
+		return "asf" // wmv
+	case hasAt(0, []byte{0x06, 0x0E, 0x2B, 0x34}):
+		return "mxf"
+	case hasAt(0, []byte{0x00, 0x00, 0x01, 0xBA}):
+		return "mpeg-ps" // vob, mpg
+	case hasAt(0, []byte{0x47}) && hasAt(188, []byte{0x47}) && hasAt(376, []byte{0x47}):
+		return "mpegts"
+	case hasAt(4, []byte{0x47}) && hasAt(196, []byte{0x47}) && hasAt(388, []byte{0x47}):
+		return "m2ts" // 패킷마다 4바이트 타임코드가 붙은 BDAV 스트림
+	}
+	return ""
+}
+
+// probeTimeout bounds the ffprobe call used for files no magic bytes matched
+const probeTimeout = 5 * time.Second
+
+// probeHasVideo asks ffprobe whether the file has a video stream. Still images,
+// which ffprobe also reports as video, are rejected.
+func probeHasVideo(path string) (bool, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	cmd, err := ffmpeg.ProbeCommand(ctx,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=codec_type:format=format_name",
+		"-of", "default=noprint_wrappers=1",
+		path,
+	)
+	if err != nil {
+		return false, err.Error()
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return false, "unrecognized file format"
+	}
+
+	values := map[string]string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			values[key] = value
+		}
+	}
+
+	format := values["format_name"]
+	if values["codec_type"] != "video" {
+		return false, "no video stream"
+	}
+	if strings.HasPrefix(format, "image2") || strings.HasSuffix(format, "_pipe") {
+		return false, fmt.Sprintf("still image (%s)", format)
+	}
+	return true, ""
+}
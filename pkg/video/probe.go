@@ -0,0 +1,172 @@
+// pkg/video/probe.go
+package video
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ProbeError is a path that could not be scanned or a file that could not be probed
+type ProbeError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Prober finds the video files under a set of paths and probes them with a
+// bounded number of concurrent ffprobe processes
+type Prober struct {
+	Workers int         // 동시에 실행할 ffprobe 수 (0이면 CPU 코어 수)
+	Cache   *ProbeCache // nil이면 항상 ffprobe 실행
+	Scan    ScanOptions // 폴더 검색 조건
+
+	// 파일 하나의 처리가 끝날 때마다 호출됨 (동시에 호출되지 않음)
+	OnResult  func(*VideoMetadata)
+	OnError   func(ProbeError)
+	OnSkipped func(SkippedFile) // 비디오가 아니라고 판단한 파일
+
+	mu sync.Mutex
+}
+
+// ProcessPaths processes multiple paths and returns video metadata for each video file
+func ProcessPaths(paths []string) ([]*VideoMetadata, error) {
+	return (&Prober{}).ProcessPaths(paths)
+}
+
+// ProcessPaths probes every video file found under paths. Deciding whether a
+// file is a video, which may itself run ffprobe, happens on the worker pool
+// too. Results are passed to OnResult as they finish and returned in the
+// order the files were found.
+func (p *Prober) ProcessPaths(paths []string) ([]*VideoMetadata, error) {
+	if err := p.Scan.Validate(); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	var errors []string
+
+	for _, path := range paths {
+		candidates, skipped, err := scanFiles(path, p.Scan)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Error processing path (%s): %v", path, err))
+			p.reportError(path, err)
+			continue
+		}
+		for _, file := range skipped {
+			p.reportSkipped(file)
+		}
+		files = append(files, candidates...)
+	}
+
+	results := make([]*VideoMetadata, len(files))
+	fileErrors := make([]error, len(files))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < p.workers(len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if ok, reason := DetectVideo(files[i]); !ok {
+					p.reportSkipped(SkippedFile{Path: files[i], Reason: reason})
+					continue
+				}
+				metadata, err := p.probe(files[i])
+				if err != nil {
+					fileErrors[i] = err
+					p.reportError(files[i], err)
+					continue
+				}
+				if reason := p.Scan.durationReason(metadata); reason != "" {
+					p.reportSkipped(SkippedFile{Path: files[i], Reason: reason})
+					continue
+				}
+				results[i] = metadata
+				p.reportResult(metadata)
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	// 발견 순서 유지, 실패하거나 건너뛴 파일은 제외
+	metadata := make([]*VideoMetadata, 0, len(files))
+	for i, result := range results {
+		if fileErrors[i] != nil {
+			errors = append(errors, fmt.Sprintf("Error processing video (%s): %v", files[i], fileErrors[i]))
+			continue
+		}
+		if result != nil {
+			metadata = append(metadata, result)
+		}
+	}
+
+	if len(errors) > 0 {
+		return metadata, fmt.Errorf("errors occurred while processing files:\n%s", strings.Join(errors, "\n"))
+	}
+
+	return metadata, nil
+}
+
+func (p *Prober) workers(files int) int {
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > files {
+		workers = files
+	}
+	return workers
+}
+
+// probe returns the cached metadata of the file or runs ffprobe on it
+func (p *Prober) probe(path string) (*VideoMetadata, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file info: %v", err)
+	}
+
+	if metadata, ok := p.Cache.Get(path, info); ok {
+		return metadata, nil
+	}
+
+	metadata, err := ProcessVideo(path)
+	if err != nil {
+		return nil, err
+	}
+	p.Cache.Put(path, info, metadata)
+	return metadata, nil
+}
+
+func (p *Prober) reportResult(metadata *VideoMetadata) {
+	if p.OnResult == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.OnResult(metadata)
+}
+
+func (p *Prober) reportError(path string, err error) {
+	if p.OnError == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.OnError(ProbeError{Path: path, Error: err.Error()})
+}
+
+func (p *Prober) reportSkipped(file SkippedFile) {
+	if p.OnSkipped == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.OnSkipped(file)
+}
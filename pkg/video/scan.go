@@ -0,0 +1,265 @@
+// pkg/video/scan.go
+package video
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ScanOptions narrows down which files are picked up when a folder is scanned
+type ScanOptions struct {
+	// 드롭한 폴더 기준 상대 경로에 대한 glob 패턴 (예: "**/RAW/*.mov")
+	// '/'가 없는 패턴은 파일 이름과 비교
+	Include []string `json:"include"` // 비어 있으면 모든 파일
+	Exclude []string `json:"exclude"`
+
+	MaxDepth    int     `json:"maxDepth"`    // 1이면 드롭한 폴더 바로 아래 파일만 (0이면 제한 없음)
+	MinSize     int64   `json:"minSize"`     // 바이트 (0이면 제한 없음)
+	MaxSize     int64   `json:"maxSize"`     // 바이트 (0이면 제한 없음)
+	MinDuration float64 `json:"minDuration"` // 초, 분석 후 적용 (0이면 제한 없음)
+
+	FollowSymlinks bool `json:"followSymlinks"` // 심볼릭 링크 폴더 안으로 들어갈지 여부 (파일 링크는 항상 포함)
+	IncludeHidden  bool `json:"includeHidden"`  // '.'으로 시작하는 파일과 폴더 포함 여부
+}
+
+// Validate checks the glob patterns and limits
+func (opts ScanOptions) Validate() error {
+	for _, pattern := range append(append([]string(nil), opts.Include...), opts.Exclude...) {
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	if opts.MaxDepth < 0 || opts.MinSize < 0 || opts.MaxSize < 0 || opts.MinDuration < 0 {
+		return fmt.Errorf("scan limits must not be negative")
+	}
+	if opts.MaxSize > 0 && opts.MinSize > opts.MaxSize {
+		return fmt.Errorf("minimum file size %d is larger than maximum %d", opts.MinSize, opts.MaxSize)
+	}
+	return nil
+}
+
+// FindVideoFiles recursively finds video files in the given path
+func FindVideoFiles(path string) ([]string, error) {
+	videos, _, err := ScanVideoFiles(path, ScanOptions{})
+	return videos, err
+}
+
+// ScanVideoFiles recursively finds video files in the given path and returns
+// the files that were passed over along with the reason. Files left out by the
+// include/exclude patterns, depth or hidden file options are not reported.
+func ScanVideoFiles(root string, opts ScanOptions) ([]string, []SkippedFile, error) {
+	files, skipped, err := scanFiles(root, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var videos []string
+	for _, file := range files {
+		if ok, reason := DetectVideo(file); ok {
+			videos = append(videos, file)
+		} else {
+			skipped = append(skipped, SkippedFile{Path: file, Reason: reason})
+		}
+	}
+	return videos, skipped, nil
+}
+
+// scanFiles walks root and returns the files that pass the scan options,
+// before content detection, which Prober runs on its worker pool
+func scanFiles(root string, opts ScanOptions) ([]string, []SkippedFile, error) {
+	s := &scanner{opts: opts, visited: map[string]bool{}}
+
+	fileInfo, err := os.Stat(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file info: %v", err)
+	}
+
+	// 직접 드롭한 파일은 패턴과 깊이 제한 없이 검사
+	if !fileInfo.IsDir() {
+		s.visitFile(root, fileInfo)
+		return s.files, s.skipped, nil
+	}
+
+	if err := s.walk(root, root, 1); err != nil {
+		return nil, nil, fmt.Errorf("error while walking directory: %v", err)
+	}
+	return s.files, s.skipped, nil
+}
+
+type scanner struct {
+	opts    ScanOptions
+	files   []string // 비디오 여부를 검사할 파일
+	skipped []SkippedFile
+	visited map[string]bool // 심볼릭 링크 순환 방지용 실제 경로
+}
+
+func (s *scanner) walk(root, dir string, depth int) error {
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		if s.visited[real] {
+			return nil
+		}
+		s.visited[real] = true
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if dir == root {
+			return err
+		}
+		// 검색 중에 삭제되거나 읽을 수 없는 하위 폴더는 건너뜀
+		s.skipped = append(s.skipped, SkippedFile{Path: dir, Reason: fmt.Sprintf("failed to read folder: %v", err)})
+		return nil
+	}
+
+	// 링크된 폴더는 실제 폴더보다 나중에 검색하여 같은 파일이 실제 경로로 나오도록 함
+	var linkedDirs []string
+
+	for _, entry := range entries {
+		if !s.opts.IncludeHidden && strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		current := filepath.Join(dir, entry.Name())
+
+		// 감시 폴더 등에서 검색 중에 삭제된 파일은 건너뜀
+		info, err := entry.Info()
+		if err != nil {
+			s.skipped = append(s.skipped, SkippedFile{Path: current, Reason: fmt.Sprintf("failed to read file info: %v", err)})
+			continue
+		}
+		if entry.Type()&os.ModeSymlink != 0 {
+			target, err := os.Stat(current)
+			if err != nil {
+				s.skipped = append(s.skipped, SkippedFile{Path: current, Reason: fmt.Sprintf("broken symbolic link: %v", err)})
+				continue
+			}
+			if target.IsDir() {
+				if s.opts.FollowSymlinks {
+					linkedDirs = append(linkedDirs, current)
+				}
+				continue
+			}
+			info = target
+		}
+
+		if info.IsDir() {
+			if s.opts.MaxDepth > 0 && depth >= s.opts.MaxDepth {
+				continue
+			}
+			if err := s.walk(root, current, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rel, err := filepath.Rel(root, current)
+		if err != nil {
+			return err
+		}
+		if !s.opts.matches(filepath.ToSlash(rel)) {
+			continue
+		}
+		s.visitFile(current, info)
+	}
+
+	if s.opts.MaxDepth > 0 && depth >= s.opts.MaxDepth {
+		return nil
+	}
+	for _, dir := range linkedDirs {
+		if err := s.walk(root, dir, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// visitFile applies the size limits to a single file. FIFOs, sockets and
+// devices are left out since opening them to detect the contents may block.
+func (s *scanner) visitFile(filePath string, info os.FileInfo) {
+	if !info.Mode().IsRegular() {
+		s.skipped = append(s.skipped, SkippedFile{Path: filePath, Reason: "not a regular file"})
+		return
+	}
+	if reason := s.opts.sizeReason(info.Size()); reason != "" {
+		s.skipped = append(s.skipped, SkippedFile{Path: filePath, Reason: reason})
+		return
+	}
+	s.files = append(s.files, filePath)
+}
+
+// sizeReason returns why a file of the given size is left out, or an empty string
+func (opts ScanOptions) sizeReason(size int64) string {
+	switch {
+	case opts.MinSize > 0 && size < opts.MinSize:
+		return fmt.Sprintf("smaller than the minimum size (%d < %d bytes)", size, opts.MinSize)
+	case opts.MaxSize > 0 && size > opts.MaxSize:
+		return fmt.Sprintf("larger than the maximum size (%d > %d bytes)", size, opts.MaxSize)
+	}
+	return ""
+}
+
+// durationReason returns why a probed file is left out, or an empty string
+func (opts ScanOptions) durationReason(metadata *VideoMetadata) string {
+	if opts.MinDuration > 0 && metadata.Duration < opts.MinDuration {
+		return fmt.Sprintf("shorter than the minimum duration (%.1fs < %.1fs)", metadata.Duration, opts.MinDuration)
+	}
+	return ""
+}
+
+// matches reports whether a slash-separated path relative to the scan root
+// passes the include and exclude patterns
+func (opts ScanOptions) matches(rel string) bool {
+	if len(opts.Include) > 0 && !matchAny(opts.Include, rel) {
+		return false
+	}
+	return !matchAny(opts.Exclude, rel)
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a relative path against a glob pattern in which ** stands
+// for any number of directories. Patterns without a slash match the file name.
+// Matching is case-insensitive, like the file systems the app mostly runs on.
+func matchGlob(pattern, rel string) bool {
+	pattern, rel = strings.ToLower(pattern), strings.ToLower(rel)
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+
+	if pattern[0] == "**" {
+		// **는 0개 이상의 디렉토리와 일치
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
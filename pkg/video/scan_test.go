@@ -0,0 +1,63 @@
+// pkg/video/scan_test.go
+package video
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{name: "leading ** matches at root", pattern: "**/RAW/*.mov", rel: "RAW/a.mov", want: true},
+		{name: "leading ** matches nested", pattern: "**/RAW/*.mov", rel: "day1/cam/RAW/a.mov", want: true},
+		{name: "leading ** needs the fixed segment", pattern: "**/RAW/*.mov", rel: "day1/a.mov", want: false},
+		{name: "leading ** does not match deeper files", pattern: "**/RAW/*.mov", rel: "RAW/sub/a.mov", want: false},
+		{name: "middle ** matches no directories", pattern: "day1/**/*.mp4", rel: "day1/a.mp4", want: true},
+		{name: "middle ** matches several directories", pattern: "day1/**/*.mp4", rel: "day1/a/b/c.mp4", want: true},
+		{name: "middle ** keeps the prefix", pattern: "day1/**/*.mp4", rel: "day2/a/c.mp4", want: false},
+		{name: "trailing ** matches everything below", pattern: "proxy/**", rel: "proxy/a/b.mp4", want: true},
+		{name: "trailing ** needs the prefix", pattern: "proxy/**", rel: "main/b.mp4", want: false},
+		{name: "no slash matches the file name at any depth", pattern: "*.mov", rel: "a/b/c.mov", want: true},
+		{name: "no slash does not match directory names", pattern: "RAW", rel: "RAW/a.mov", want: false},
+		{name: "case-insensitive", pattern: "**/raw/*.MOV", rel: "Day1/RAW/a.mov", want: true},
+		{name: "single segment pattern with slash is anchored", pattern: "a/*.mp4", rel: "x/a/b.mp4", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchGlob(tt.pattern, tt.rel); got != tt.want {
+				t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanOptionsMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ScanOptions
+		rel  string
+		want bool
+	}{
+		{name: "no patterns", opts: ScanOptions{}, rel: "a/b.mp4", want: true},
+		{name: "included", opts: ScanOptions{Include: []string{"**/RAW/*.mov"}}, rel: "x/RAW/a.mov", want: true},
+		{name: "not included", opts: ScanOptions{Include: []string{"**/RAW/*.mov"}}, rel: "x/a.mov", want: false},
+		{name: "excluded", opts: ScanOptions{Exclude: []string{"*.tmp.mp4"}}, rel: "a.tmp.mp4", want: false},
+		{
+			name: "exclude takes priority over include",
+			opts: ScanOptions{Include: []string{"**/RAW/*.mov"}, Exclude: []string{"**/RAW/proxy_*"}},
+			rel:  "x/RAW/proxy_a.mov",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.matches(tt.rel); got != tt.want {
+				t.Errorf("matches(%q) = %v, want %v", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !windows
+
+// pkg/video/scan_unix_test.go
+package video
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestScanFilesSkipsFIFO(t *testing.T) {
+	dir := t.TempDir()
+	fifo := filepath.Join(dir, "pipe")
+	if err := syscall.Mkfifo(fifo, 0644); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+	clip := filepath.Join(dir, "clip.mp4")
+	if err := os.WriteFile(clip, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, skipped, err := scanFiles(dir, ScanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != clip {
+		t.Errorf("files = %v, want [%s]", files, clip)
+	}
+	if len(skipped) != 1 || skipped[0].Path != fifo {
+		t.Errorf("skipped = %+v, want the FIFO", skipped)
+	}
+}
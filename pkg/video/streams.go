@@ -0,0 +1,176 @@
+// pkg/video/streams.go
+package video
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VideoStream describes a video stream reported by ffprobe
+type VideoStream struct {
+	Index          int     `json:"index"`
+	Codec          string  `json:"codec"`
+	Profile        string  `json:"profile"`
+	Width          int     `json:"width"`
+	Height         int     `json:"height"`
+	FrameRate      float64 `json:"frameRate"`
+	PixelFormat    string  `json:"pixelFormat"`
+	BitDepth       int     `json:"bitDepth"`
+	ColorPrimaries string  `json:"colorPrimaries"`
+	ColorTransfer  string  `json:"colorTransfer"`
+	ColorSpace     string  `json:"colorSpace"`
+	Rotation       int     `json:"rotation"` // 표시할 때 시계 방향으로 회전할 각도 (0, 90, 180, 270)
+	Bitrate        int64   `json:"bitrate"`  // bps (알 수 없으면 0)
+}
+
+// AudioStream describes an audio stream reported by ffprobe
+type AudioStream struct {
+	Index         int    `json:"index"`
+	Codec         string `json:"codec"`
+	Channels      int    `json:"channels"`
+	ChannelLayout string `json:"channelLayout"`
+	SampleRate    int    `json:"sampleRate"`
+	Language      string `json:"language"`
+	Title         string `json:"title"`
+	Bitrate       int64  `json:"bitrate"`
+}
+
+// SubtitleStream describes a subtitle stream reported by ffprobe
+type SubtitleStream struct {
+	Index    int    `json:"index"`
+	Codec    string `json:"codec"`
+	Language string `json:"language"`
+	Title    string `json:"title"`
+}
+
+// probeStream is a stream entry of `ffprobe -show_streams -print_format json`
+type probeStream struct {
+	Index            int    `json:"index"`
+	CodecName        string `json:"codec_name"`
+	CodecType        string `json:"codec_type"`
+	Profile          string `json:"profile"`
+	Width            int    `json:"width"`
+	Height           int    `json:"height"`
+	RFrameRate       string `json:"r_frame_rate"`
+	AvgFrameRate     string `json:"avg_frame_rate"`
+	PixFmt           string `json:"pix_fmt"`
+	BitsPerRawSample string `json:"bits_per_raw_sample"`
+	ColorPrimaries   string `json:"color_primaries"`
+	ColorTransfer    string `json:"color_transfer"`
+	ColorSpace       string `json:"color_space"`
+	BitRate          string `json:"bit_rate"`
+	Channels         int    `json:"channels"`
+	ChannelLayout    string `json:"channel_layout"`
+	SampleRate       string `json:"sample_rate"`
+	Tags             struct {
+		Language string `json:"language"`
+		Title    string `json:"title"`
+		Rotate   string `json:"rotate"`
+	} `json:"tags"`
+	SideDataList []struct {
+		SideDataType string  `json:"side_data_type"`
+		Rotation     float64 `json:"rotation"`
+	} `json:"side_data_list"`
+}
+
+func (s probeStream) videoStream() VideoStream {
+	frameRate := parseRate(s.AvgFrameRate)
+	if frameRate == 0 {
+		frameRate = parseRate(s.RFrameRate)
+	}
+
+	return VideoStream{
+		Index:          s.Index,
+		Codec:          s.CodecName,
+		Profile:        s.Profile,
+		Width:          s.Width,
+		Height:         s.Height,
+		FrameRate:      frameRate,
+		PixelFormat:    s.PixFmt,
+		BitDepth:       s.bitDepth(),
+		ColorPrimaries: s.ColorPrimaries,
+		ColorTransfer:  s.ColorTransfer,
+		ColorSpace:     s.ColorSpace,
+		Rotation:       s.rotation(),
+		Bitrate:        parseInt64(s.BitRate),
+	}
+}
+
+func (s probeStream) audioStream() AudioStream {
+	return AudioStream{
+		Index:         s.Index,
+		Codec:         s.CodecName,
+		Channels:      s.Channels,
+		ChannelLayout: s.ChannelLayout,
+		SampleRate:    int(parseInt64(s.SampleRate)),
+		Language:      s.Tags.Language,
+		Title:         s.Tags.Title,
+		Bitrate:       parseInt64(s.BitRate),
+	}
+}
+
+func (s probeStream) subtitleStream() SubtitleStream {
+	return SubtitleStream{
+		Index:    s.Index,
+		Codec:    s.CodecName,
+		Language: s.Tags.Language,
+		Title:    s.Tags.Title,
+	}
+}
+
+// pixFmtDepthRegex matches the bit depth in pixel formats such as yuv420p10le
+var pixFmtDepthRegex = regexp.MustCompile(`p(\d{2})(?:le|be)$`)
+
+// bitDepth returns bits_per_raw_sample, falling back to the pixel format name
+func (s probeStream) bitDepth() int {
+	if depth, err := strconv.Atoi(s.BitsPerRawSample); err == nil && depth > 0 {
+		return depth
+	}
+	if matches := pixFmtDepthRegex.FindStringSubmatch(s.PixFmt); len(matches) > 1 {
+		depth, _ := strconv.Atoi(matches[1])
+		return depth
+	}
+	if s.PixFmt != "" {
+		return 8
+	}
+	return 0
+}
+
+// rotation returns the clockwise display rotation in degrees. Older ffprobe
+// builds report a rotate tag, newer ones a display matrix with the opposite sign.
+func (s probeStream) rotation() int {
+	degrees := 0
+	if rotate, err := strconv.Atoi(s.Tags.Rotate); err == nil {
+		degrees = rotate
+	} else {
+		for _, sideData := range s.SideDataList {
+			if sideData.SideDataType == "Display Matrix" {
+				degrees = -int(math.Round(sideData.Rotation))
+				break
+			}
+		}
+	}
+	return ((degrees % 360) + 360) % 360
+}
+
+// parseRate parses a frame rate like 30000/1001
+func parseRate(rate string) float64 {
+	num, den, ok := strings.Cut(rate, "/")
+	if !ok {
+		value, _ := strconv.ParseFloat(rate, 64)
+		return value
+	}
+	n, _ := strconv.ParseFloat(num, 64)
+	d, _ := strconv.ParseFloat(den, 64)
+	if d == 0 {
+		return 0
+	}
+	return n / d
+}
+
+func parseInt64(value string) int64 {
+	n, _ := strconv.ParseInt(value, 10, 64)
+	return n
+}
@@ -2,13 +2,14 @@
 package video
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+
+	"encoder/pkg/ffmpeg"
 )
 
 type VideoMetadata struct {
@@ -18,77 +19,29 @@ type VideoMetadata struct {
 	Format   string  `json:"format"`
 	Codec    string  `json:"codec"`
 	Path     string  `json:"path"`
-}
-
-// 지원하는 비디오 확장자 목록
-var supportedExtensions = map[string]bool{
-	".mp4":  true,
-	".avi":  true,
-	".mov":  true,
-	".mkv":  true,
-	".wmv":  true,
-	".flv":  true,
-	".webm": true,
-}
-
-// IsVideoFile checks if the file is a video file
-func IsVideoFile(path string) bool {
-	ext := strings.ToLower(filepath.Ext(path))
-	return supportedExtensions[ext]
-}
-
-// FindVideoFiles recursively finds video files in the given path
-func FindVideoFiles(path string) ([]string, error) {
-	var videos []string
-
-	fileInfo, err := os.Stat(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file info: %v", err)
-	}
-
-	if !fileInfo.IsDir() {
-		if IsVideoFile(path) {
-			return []string{path}, nil
-		}
-		return nil, nil
-	}
-
-	err = filepath.Walk(path, func(currentPath string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if strings.HasPrefix(info.Name(), ".") {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
+	Width    int     `json:"width"`
+	Height   int     `json:"height"`
+	Bitrate  int64   `json:"bitrate"` // 전체 비트레이트 (bps)
 
-		if !info.IsDir() && IsVideoFile(currentPath) {
-			videos = append(videos, currentPath)
-		}
-		return nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("error while walking directory: %v", err)
-	}
-
-	return videos, nil
+	VideoStreams    []VideoStream    `json:"videoStreams"`
+	AudioStreams    []AudioStream    `json:"audioStreams"`
+	SubtitleStreams []SubtitleStream `json:"subtitleStreams"`
 }
 
 // ProcessVideo processes a single video file and returns its metadata
 func ProcessVideo(filePath string) (*VideoMetadata, error) {
 	fileName := filepath.Base(filePath)
 
-	cmd := exec.Command("ffprobe",
+	cmd, err := ffmpeg.ProbeCommand(context.Background(),
 		"-v", "quiet",
 		"-print_format", "json",
 		"-show_format",
 		"-show_streams",
 		filePath,
 	)
+	if err != nil {
+		return nil, err
+	}
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -96,14 +49,13 @@ func ProcessVideo(filePath string) (*VideoMetadata, error) {
 	}
 
 	var probe struct {
-		Streams []struct {
-			CodecName string `json:"codec_name"`
-		} `json:"streams"`
-		Format struct {
+		Streams []probeStream `json:"streams"`
+		Format  struct {
 			Filename string `json:"filename"`
 			Size     string `json:"size"`
 			Duration string `json:"duration"`
 			Format   string `json:"format_name"`
+			BitRate  string `json:"bit_rate"`
 		} `json:"format"`
 	}
 
@@ -114,49 +66,32 @@ func ProcessVideo(filePath string) (*VideoMetadata, error) {
 	duration, _ := strconv.ParseFloat(probe.Format.Duration, 64)
 	size, _ := strconv.ParseInt(probe.Format.Size, 10, 64)
 
-	videoCodec := ""
-	for _, stream := range probe.Streams {
-		if stream.CodecName != "" {
-			videoCodec = stream.CodecName
-			break
-		}
-	}
-
-	return &VideoMetadata{
+	metadata := &VideoMetadata{
 		Name:     fileName,
 		Size:     size,
 		Duration: duration,
 		Format:   strings.Split(probe.Format.Format, ",")[0],
-		Codec:    videoCodec,
 		Path:     filePath,
-	}, nil
-}
-
-// ProcessPaths processes multiple paths and returns video metadata for each video file
-func ProcessPaths(paths []string) ([]*VideoMetadata, error) {
-	var results []*VideoMetadata
-	var errors []string
-
-	for _, path := range paths {
-		videoPaths, err := FindVideoFiles(path)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("Error processing path (%s): %v", path, err))
-			continue
-		}
+		Bitrate:  parseInt64(probe.Format.BitRate),
+	}
 
-		for _, videoPath := range videoPaths {
-			metadata, err := ProcessVideo(videoPath)
-			if err != nil {
-				errors = append(errors, fmt.Sprintf("Error processing video (%s): %v", videoPath, err))
-				continue
-			}
-			results = append(results, metadata)
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "video":
+			metadata.VideoStreams = append(metadata.VideoStreams, stream.videoStream())
+		case "audio":
+			metadata.AudioStreams = append(metadata.AudioStreams, stream.audioStream())
+		case "subtitle":
+			metadata.SubtitleStreams = append(metadata.SubtitleStreams, stream.subtitleStream())
 		}
 	}
 
-	if len(errors) > 0 {
-		return results, fmt.Errorf("errors occurred while processing files:\n%s", strings.Join(errors, "\n"))
+	// 코덱과 해상도는 첫 번째 비디오 스트림 기준
+	if len(metadata.VideoStreams) > 0 {
+		first := metadata.VideoStreams[0]
+		metadata.Codec = first.Codec
+		metadata.Width, metadata.Height = first.Width, first.Height
 	}
 
-	return results, nil
+	return metadata, nil
 }
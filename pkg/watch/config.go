@@ -0,0 +1,113 @@
+// pkg/watch/config.go
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"encoder/pkg/encoder"
+	"encoder/pkg/fileutil"
+)
+
+const (
+	doneDir   = "done"   // 인코딩에 성공한 원본을 옮기는 하위 폴더
+	failedDir = "failed" // 분석 또는 인코딩에 실패한 원본을 옮기는 하위 폴더
+)
+
+// Folder is a watched directory and the encoding preset for the files dropped into it
+type Folder struct {
+	Path      string                  `json:"path"`
+	Recursive bool                    `json:"recursive"` // 하위 폴더도 감시
+	Options   encoder.EncodingOptions `json:"options"`   // OutputPath는 출력 디렉토리
+}
+
+// Validate checks the folder and its preset. Outputs must go to a directory,
+// since writing them next to the sources would make the watcher pick them up.
+func (f *Folder) Validate() error {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read watch folder (%s): %w", f.Path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("watch folder is not a directory: %s", f.Path)
+	}
+
+	if f.Options.OutputPath == "" {
+		return fmt.Errorf("watch folder %s needs an output directory", f.Path)
+	}
+	if filepath.Clean(f.Options.OutputPath) == filepath.Clean(f.Path) {
+		return fmt.Errorf("output directory must differ from the watch folder: %s", f.Path)
+	}
+
+	if err := f.Options.Validate(); err != nil {
+		return fmt.Errorf("invalid encoding options for watch folder %s: %w", f.Path, err)
+	}
+	return nil
+}
+
+// options returns the preset used to enqueue a file of this folder
+func (f *Folder) options() encoder.EncodingOptions {
+	options := f.Options
+	// 출력 디렉토리가 아직 없거나 마운트되지 않았어도 파일 이름이 아닌 디렉토리로 취급
+	if !strings.HasSuffix(options.OutputPath, string(filepath.Separator)) {
+		options.OutputPath += string(filepath.Separator)
+	}
+	// 하위 폴더 구조는 감시 폴더 기준으로 재현
+	if options.MirrorTree {
+		options.SourceRoots = []string{f.Path}
+	}
+	return options
+}
+
+// DefaultConfigPath returns the watch folder list location in the user config directory
+func DefaultConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "encoder", "watch.json"), nil
+}
+
+// loadFolders reads the watch folders stored at path. A missing file yields no
+// folders. It also returns the path to save changes to: a file that cannot be
+// parsed is renamed to path.bad, and if it cannot be read or moved the
+// returned path is empty so the list is not overwritten.
+func loadFolders(path string) ([]Folder, string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, path, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read watch folder list (%s), changes will not be saved: %w", path, err)
+	}
+
+	var folders []Folder
+	if err := json.Unmarshal(data, &folders); err != nil {
+		badPath, renameErr := fileutil.MoveAside(path)
+		if renameErr != nil {
+			return nil, "", fmt.Errorf("failed to parse watch folder list (%s), changes will not be saved: %w", path, err)
+		}
+		return nil, path, fmt.Errorf("failed to parse watch folder list, moved it to %s: %w", badPath, err)
+	}
+	return folders, path, nil
+}
+
+// saveFolders writes the watch folders to disk
+func saveFolders(path string, folders []Folder) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(folders, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode watch folder list: %w", err)
+	}
+	if err := fileutil.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to save watch folder list (%s): %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,483 @@
+// pkg/watch/watch.go
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"encoder/pkg/encoder"
+	"encoder/pkg/ffmpeg"
+	"encoder/pkg/fileutil"
+	"encoder/pkg/video"
+)
+
+const (
+	defaultPollInterval = 2 * time.Second
+	defaultStableFor    = 5 * time.Second
+
+	// maxProbeAttempts is how many times a file that ffprobe cannot read is
+	// tried before it is moved to failed/. A file whose size stopped changing
+	// may still be locked by its writer, e.g. on SMB shares or Windows.
+	maxProbeAttempts = 3
+)
+
+// errProbeUnavailable means ffprobe could not be run, which is not a problem with the file
+var errProbeUnavailable = errors.New("ffprobe is not available")
+
+// Event reports what the watcher did with a file
+type Event struct {
+	Type   string `json:"type"` // enqueued, done, failed, skipped (출력 파일이 이미 있음), error (인코딩을 시작하지 못함)
+	Folder string `json:"folder"`
+	Path   string `json:"path"` // done/failed 이벤트에서는 옮긴 뒤의 경로
+	JobID  string `json:"jobId,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Watcher polls the watch folders for new files. Once a file has stopped
+// growing it is probed and added to the job queue with the folder's preset,
+// and after its job finishes the source is moved to done/ or failed/, or left
+// in place if the job was skipped because its output already exists.
+type Watcher struct {
+	PollInterval time.Duration // 기본값: 2초
+	StableFor    time.Duration // 크기와 수정 시간이 이 시간 동안 같아야 복사가 끝난 것으로 봄 (기본값: 5초)
+	OnEvent      func(Event)
+
+	queue      *encoder.Queue
+	run        func(ids []string) (*encoder.BatchResult, error)
+	probe      func(path string) error // 파일을 읽을 수 있는지 확인 (테스트에서 교체)
+	configPath string
+
+	mu           sync.Mutex
+	folders      []Folder
+	running      bool
+	lastRunError string // 같은 오류를 폴링마다 다시 보고하지 않기 위함
+
+	// 아래 필드는 poll에서만 사용
+	files    map[string]fileState  // 크기가 안정되기를 기다리는 파일
+	ignored  map[string]fileState  // 비디오가 아닌 파일 (변경되면 다시 검사)
+	attempts map[string]int        // 분석에 실패한 횟수
+	jobs     map[string]trackedJob // 작업 ID별 원본 파일
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+	since   time.Time // 마지막으로 변경을 확인한 시각
+}
+
+func (s fileState) same(info os.FileInfo) bool {
+	return s.size == info.Size() && s.modTime.Equal(info.ModTime())
+}
+
+type trackedJob struct {
+	folder string
+	source string
+}
+
+// New loads the watch folders stored at configPath. run encodes the given
+// queue jobs and is called by the watcher for the files it enqueues. Jobs
+// left in the queue by a previous session for files in a watch folder are
+// picked up again.
+func New(configPath string, queue *encoder.Queue, run func(ids []string) (*encoder.BatchResult, error)) (*Watcher, error) {
+	w := &Watcher{
+		queue:    queue,
+		run:      run,
+		probe:    probeFile,
+		files:    map[string]fileState{},
+		ignored:  map[string]fileState{},
+		attempts: map[string]int{},
+		jobs:     map[string]trackedJob{},
+	}
+
+	folders, savePath, err := loadFolders(configPath)
+	w.configPath = savePath
+	if err != nil {
+		return w, err
+	}
+	w.folders = folders
+
+	for _, job := range queue.List() {
+		if _, err := os.Stat(job.InputPath); err != nil {
+			continue
+		}
+		for _, folder := range folders {
+			if watched(folder, job.InputPath) {
+				w.jobs[job.ID] = trackedJob{folder: folder.Path, source: job.InputPath}
+				break
+			}
+		}
+	}
+
+	return w, nil
+}
+
+// Folders returns the watch folders
+func (w *Watcher) Folders() []Folder {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]Folder(nil), w.folders...)
+}
+
+// AddFolder starts watching a folder, replacing the preset if it is already watched
+func (w *Watcher) AddFolder(folder Folder) error {
+	folder.Path = filepath.Clean(folder.Path)
+	if err := folder.Validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(folder.Options.OutputPath, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i := range w.folders {
+		if w.folders[i].Path == folder.Path {
+			w.folders[i] = folder
+			return saveFolders(w.configPath, w.folders)
+		}
+	}
+	w.folders = append(w.folders, folder)
+	return saveFolders(w.configPath, w.folders)
+}
+
+// RemoveFolder stops watching a folder. Files already in the queue are still encoded.
+func (w *Watcher) RemoveFolder(path string) error {
+	path = filepath.Clean(path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i := range w.folders {
+		if w.folders[i].Path == path {
+			w.folders = append(w.folders[:i], w.folders[i+1:]...)
+			return saveFolders(w.configPath, w.folders)
+		}
+	}
+	return fmt.Errorf("folder is not watched: %s", path)
+}
+
+// Run polls the watch folders until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	interval := w.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		w.poll()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll moves the sources of finished jobs, picks up files that stopped
+// changing and starts encoding the queued ones
+func (w *Watcher) poll() {
+	w.settle()
+
+	stableFor := w.StableFor
+	if stableFor <= 0 {
+		stableFor = defaultStableFor
+	}
+
+	now := time.Now()
+	present := map[string]bool{}
+	sources := w.sources()
+
+	for _, folder := range w.Folders() {
+		for path, info := range scanFolder(folder) {
+			present[path] = true
+			if sources[path] {
+				continue
+			}
+			if state, ok := w.ignored[path]; ok && state.same(info) {
+				continue
+			}
+			delete(w.ignored, path)
+
+			state, ok := w.files[path]
+			if !ok || !state.same(info) {
+				w.files[path] = fileState{size: info.Size(), modTime: info.ModTime(), since: now}
+				continue
+			}
+			if now.Sub(state.since) < stableFor {
+				continue
+			}
+
+			delete(w.files, path)
+			w.admit(folder, path, state)
+		}
+	}
+
+	// 사라진 파일 정리
+	for path := range w.files {
+		if !present[path] {
+			delete(w.files, path)
+		}
+	}
+	for path := range w.ignored {
+		if !present[path] {
+			delete(w.ignored, path)
+		}
+	}
+	for path := range w.attempts {
+		if !present[path] {
+			delete(w.attempts, path)
+		}
+	}
+
+	w.startRun()
+}
+
+// admit probes a file that stopped changing and adds it to the queue
+func (w *Watcher) admit(folder Folder, path string, state fileState) {
+	if ok, _ := video.DetectVideo(path); !ok {
+		w.ignored[path] = state
+		return
+	}
+
+	if err := w.probe(path); err != nil {
+		// ffprobe가 없으면 파일 문제가 아니므로 그대로 두고 다시 시도
+		if errors.Is(err, errProbeUnavailable) {
+			return
+		}
+		// 쓰기가 끝나지 않아 잠긴 파일일 수 있으므로 다시 안정될 때까지 기다린 뒤 재시도
+		w.attempts[path]++
+		if w.attempts[path] < maxProbeAttempts {
+			w.files[path] = fileState{size: state.size, modTime: state.modTime, since: time.Now()}
+			return
+		}
+		delete(w.attempts, path)
+		w.moveSource(folder.Path, path, failedDir, "", err)
+		return
+	}
+	delete(w.attempts, path)
+
+	jobs, err := w.queue.Add([]string{path}, folder.options())
+	if err != nil {
+		w.moveSource(folder.Path, path, failedDir, "", err)
+		return
+	}
+
+	for _, job := range jobs {
+		w.jobs[job.ID] = trackedJob{folder: folder.Path, source: path}
+		w.emit(Event{Type: "enqueued", Folder: folder.Path, Path: path, JobID: job.ID})
+	}
+}
+
+// settle moves the sources of finished jobs to done/ or failed/ and leaves
+// skipped ones in place
+func (w *Watcher) settle() {
+	if len(w.jobs) == 0 {
+		return
+	}
+
+	statuses := map[string]encoder.Job{}
+	for _, job := range w.queue.List() {
+		statuses[job.ID] = job
+	}
+
+	for id, tracked := range w.jobs {
+		job, ok := statuses[id]
+		if !ok {
+			// 사용자가 큐에서 지운 작업: 원본을 그대로 두고 변경될 때까지 다시 추가하지 않음
+			delete(w.jobs, id)
+			w.ignore(tracked.source)
+			continue
+		}
+
+		switch job.Status {
+		case encoder.JobCompleted:
+			delete(w.jobs, id)
+			w.moveSource(tracked.folder, tracked.source, doneDir, id, nil)
+		case encoder.JobSkipped:
+			// 출력 파일이 이미 있어 인코딩하지 않은 원본은 그대로 둠
+			delete(w.jobs, id)
+			w.ignore(tracked.source)
+			w.emit(Event{Type: "skipped", Folder: tracked.folder, Path: tracked.source, JobID: id, Error: job.Error})
+		case encoder.JobFailed, encoder.JobCancelled:
+			delete(w.jobs, id)
+			var jobErr error
+			if job.Error != "" {
+				jobErr = fmt.Errorf("%s", job.Error)
+			}
+			w.moveSource(tracked.folder, tracked.source, failedDir, id, jobErr)
+		}
+	}
+}
+
+// startRun encodes the queued jobs of the watcher unless a previous run is
+// still going. If the batch cannot start, e.g. because ffmpeg is missing or
+// another batch is running, an error event is sent once and the jobs stay
+// queued to be tried again on the next poll.
+func (w *Watcher) startRun() {
+	var ids []string
+	statuses := map[string]encoder.JobStatus{}
+	for _, job := range w.queue.List() {
+		statuses[job.ID] = job.Status
+	}
+	for id := range w.jobs {
+		if statuses[id] == encoder.JobQueued {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	go func() {
+		defer func() {
+			w.mu.Lock()
+			w.running = false
+			w.mu.Unlock()
+		}()
+		_, err := w.run(ids)
+		w.reportRunError(err)
+	}()
+}
+
+// reportRunError sends an error event when a run could not start, unless the
+// previous run failed with the same error
+func (w *Watcher) reportRunError(err error) {
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+
+	w.mu.Lock()
+	changed := message != w.lastRunError
+	w.lastRunError = message
+	w.mu.Unlock()
+
+	if err != nil && changed {
+		w.emit(Event{Type: "error", Error: fmt.Sprintf("failed to start encoding watched files: %v", err)})
+	}
+}
+
+// ignore leaves a source in place and does not enqueue it again until it changes
+func (w *Watcher) ignore(path string) {
+	if info, err := os.Stat(path); err == nil {
+		w.ignored[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+	}
+}
+
+// sources returns the files that have a job in the queue
+func (w *Watcher) sources() map[string]bool {
+	sources := make(map[string]bool, len(w.jobs))
+	for _, tracked := range w.jobs {
+		sources[tracked.source] = true
+	}
+	return sources
+}
+
+// moveSource moves a source file into the done/ or failed/ subfolder of its
+// watch folder, keeping its path relative to the folder
+func (w *Watcher) moveSource(folder, source, subdir, jobID string, cause error) {
+	event := Event{Type: subdir, Folder: folder, Path: source, JobID: jobID}
+	if cause != nil {
+		event.Error = cause.Error()
+	}
+
+	rel, err := filepath.Rel(folder, source)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(source)
+	}
+	dest := fileutil.NextFreePath(filepath.Join(folder, subdir, rel), fileutil.Exists)
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		event.Error = fmt.Sprintf("failed to create %s folder: %v", subdir, err)
+	} else if err := os.Rename(source, dest); err != nil {
+		event.Error = fmt.Sprintf("failed to move source to %s: %v", subdir, err)
+	} else {
+		event.Path = dest
+	}
+
+	w.emit(event)
+}
+
+func (w *Watcher) emit(event Event) {
+	if w.OnEvent != nil {
+		w.OnEvent(event)
+	}
+}
+
+// probeFile checks that ffprobe can read the file
+func probeFile(path string) error {
+	if _, err := ffmpeg.FFprobePath(); err != nil {
+		return fmt.Errorf("%w: %v", errProbeUnavailable, err)
+	}
+	_, err := video.ProcessVideo(path)
+	return err
+}
+
+// scanFolder lists the files of a watch folder, leaving out hidden files,
+// the done/ and failed/ subfolders and an output directory inside the folder
+func scanFolder(folder Folder) map[string]os.FileInfo {
+	files := map[string]os.FileInfo{}
+	skipDirs := map[string]bool{
+		filepath.Join(folder.Path, doneDir):       true,
+		filepath.Join(folder.Path, failedDir):     true,
+		filepath.Clean(folder.Options.OutputPath): true,
+	}
+
+	var walk func(dir string)
+	walk = func(dir string) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if entry.IsDir() {
+				if folder.Recursive && !skipDirs[path] {
+					walk(path)
+				}
+				continue
+			}
+			if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+				files[path] = info
+			}
+		}
+	}
+	walk(folder.Path)
+
+	return files
+}
+
+// watched reports whether path is a file the folder would pick up
+func watched(folder Folder, path string) bool {
+	rel, err := filepath.Rel(folder.Path, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	if len(parts) > 1 && (!folder.Recursive || parts[0] == doneDir || parts[0] == failedDir) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,255 @@
+// pkg/watch/watch_test.go
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"encoder/pkg/encoder"
+)
+
+// newTestWatcher creates a watcher for a temporary watch folder whose files
+// always probe successfully and whose runs are recorded instead of encoded
+func newTestWatcher(t *testing.T, queue *encoder.Queue) (*Watcher, Folder, chan []string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	folder := Folder{
+		Path:      filepath.Join(dir, "in"),
+		Recursive: true,
+		Options:   encoder.EncodingOptions{VideoFormat: "mp4", OutputPath: filepath.Join(dir, "out")},
+	}
+	if err := os.MkdirAll(folder.Path, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	runs := make(chan []string, 10)
+	w, err := New("", queue, func(ids []string) (*encoder.BatchResult, error) {
+		runs <- ids
+		return &encoder.BatchResult{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.folders = []Folder{folder}
+	w.probe = func(string) error { return nil }
+	w.StableFor = time.Millisecond
+	return w, folder, runs
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPollWaitsUntilFileIsStable(t *testing.T) {
+	queue := &encoder.Queue{}
+	w, folder, runs := newTestWatcher(t, queue)
+	source := filepath.Join(folder.Path, "clip.mp4")
+
+	writeFile(t, source, "part")
+	w.poll()
+	if jobs := queue.List(); len(jobs) != 0 {
+		t.Fatalf("file enqueued on first sight: %v", jobs)
+	}
+
+	// 파일이 계속 커지는 동안에는 대기
+	time.Sleep(5 * time.Millisecond)
+	writeFile(t, source, "partial")
+	w.poll()
+	if jobs := queue.List(); len(jobs) != 0 {
+		t.Fatalf("file enqueued while still growing: %v", jobs)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	w.poll()
+	jobs := queue.List()
+	if len(jobs) != 1 || jobs[0].InputPath != source {
+		t.Fatalf("jobs = %v, want one job for %s", jobs, source)
+	}
+	if want := filepath.Join(folder.Options.OutputPath, "clip.mp4"); jobs[0].OutputPath != want {
+		t.Errorf("output path = %s, want %s", jobs[0].OutputPath, want)
+	}
+
+	select {
+	case ids := <-runs:
+		if len(ids) != 1 || ids[0] != jobs[0].ID {
+			t.Errorf("run ids = %v, want [%s]", ids, jobs[0].ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued job was not run")
+	}
+
+	// 큐에 들어간 파일은 다시 추가하지 않음
+	time.Sleep(5 * time.Millisecond)
+	w.poll()
+	if jobs := queue.List(); len(jobs) != 1 {
+		t.Errorf("file enqueued twice: %v", jobs)
+	}
+}
+
+func TestPollRetriesProbeFailures(t *testing.T) {
+	queue := &encoder.Queue{}
+	w, folder, _ := newTestWatcher(t, queue)
+	source := filepath.Join(folder.Path, "locked.mp4")
+	writeFile(t, source, "data")
+
+	probes := 0
+	w.probe = func(string) error {
+		probes++
+		return errors.New("file is locked")
+	}
+	var events []Event
+	w.OnEvent = func(event Event) { events = append(events, event) }
+
+	w.poll()
+	for i := 1; i < maxProbeAttempts; i++ {
+		time.Sleep(5 * time.Millisecond)
+		w.poll()
+		if _, err := os.Stat(source); err != nil {
+			t.Fatalf("source moved after %d failed probes: %v", probes, err)
+		}
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	w.poll()
+	if probes != maxProbeAttempts {
+		t.Errorf("probes = %d, want %d", probes, maxProbeAttempts)
+	}
+	moved := filepath.Join(folder.Path, failedDir, "locked.mp4")
+	if _, err := os.Stat(moved); err != nil {
+		t.Fatalf("source not moved to failed/: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != "failed" || events[0].Path != moved {
+		t.Errorf("events = %+v, want one failed event for %s", events, moved)
+	}
+	if jobs := queue.List(); len(jobs) != 0 {
+		t.Errorf("unreadable file was enqueued: %v", jobs)
+	}
+}
+
+func TestNewResumesJobsAndSettlesThem(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in")
+	outside := filepath.Join(dir, "other", "c.mp4")
+	completed := filepath.Join(in, "a.mp4")
+	failed := filepath.Join(in, "sub", "b.mp4")
+	skipped := filepath.Join(in, "s.mp4")
+	for _, path := range []string{completed, failed, skipped, outside} {
+		writeFile(t, path, "data")
+	}
+
+	// 이전 실행에서 남은 큐와 감시 폴더 목록
+	queuePath := filepath.Join(dir, "queue.json")
+	jobs := []encoder.Job{
+		{ID: "1", InputPath: completed, Status: encoder.JobCompleted},
+		{ID: "2", InputPath: failed, Status: encoder.JobFailed, Error: "encoding failed"},
+		{ID: "3", InputPath: outside, Status: encoder.JobCompleted},
+		{ID: "4", InputPath: skipped, Status: encoder.JobSkipped, Error: "output file already exists"},
+	}
+	data, _ := json.Marshal(jobs)
+	writeFile(t, queuePath, string(data))
+	queue, err := encoder.LoadQueue(queuePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(dir, "watch.json")
+	data, _ = json.Marshal([]Folder{{
+		Path:      in,
+		Recursive: true,
+		Options:   encoder.EncodingOptions{VideoFormat: "mp4", OutputPath: filepath.Join(dir, "out")},
+	}})
+	writeFile(t, configPath, string(data))
+
+	w, err := New(configPath, queue, func([]string) (*encoder.BatchResult, error) {
+		return &encoder.BatchResult{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.probe = func(string) error { return nil }
+	var events []Event
+	w.OnEvent = func(event Event) { events = append(events, event) }
+
+	w.poll()
+
+	for _, path := range []string{
+		filepath.Join(in, doneDir, "a.mp4"),
+		filepath.Join(in, failedDir, "sub", "b.mp4"),
+		skipped,
+		outside,
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s: %v", path, err)
+		}
+	}
+	for _, path := range []string{completed, failed} {
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("%s was not moved", path)
+		}
+	}
+	if len(events) != 3 {
+		t.Errorf("events = %+v, want done, failed and skipped", events)
+	}
+
+	// 옮긴 파일은 done/, failed/ 아래에 있고 건너뛴 파일은 변경될 때까지 무시하므로 다시 큐에 추가하지 않음
+	time.Sleep(5 * time.Millisecond)
+	w.poll()
+	if got := len(queue.List()); got != len(jobs) {
+		t.Errorf("queue has %d jobs, want %d", got, len(jobs))
+	}
+}
+
+func TestRunErrorIsReportedOnce(t *testing.T) {
+	queue := &encoder.Queue{}
+	w, folder, _ := newTestWatcher(t, queue)
+	done := make(chan struct{}, 10)
+	w.run = func([]string) (*encoder.BatchResult, error) {
+		defer func() { done <- struct{}{} }()
+		return nil, errors.New("encoding is already in progress")
+	}
+	events := make(chan Event, 10)
+	w.OnEvent = func(event Event) { events <- event }
+
+	writeFile(t, filepath.Join(folder.Path, "clip.mp4"), "data")
+	w.poll()
+	time.Sleep(5 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		w.poll()
+		<-done
+		// 실행 중 표시가 풀릴 때까지 대기
+		for {
+			w.mu.Lock()
+			running := w.running
+			w.mu.Unlock()
+			if !running {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	close(events)
+
+	var errorEvents int
+	for event := range events {
+		if event.Type == "error" {
+			errorEvents++
+		}
+	}
+	if errorEvents != 1 {
+		t.Errorf("error events = %d, want 1", errorEvents)
+	}
+	if jobs := queue.List(); len(jobs) != 1 || jobs[0].Status != encoder.JobQueued {
+		t.Errorf("jobs = %v, want one queued job", jobs)
+	}
+}